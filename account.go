@@ -0,0 +1,68 @@
+package matchguru
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	fbauth "firebase.google.com/go/v4/auth"
+	"github.com/klipach/matchguru/account"
+	"github.com/klipach/matchguru/auth"
+	"github.com/klipach/matchguru/log"
+)
+
+// AuthEvent is the payload of a Firebase Auth trigger, only the fields the hooks use are decoded.
+type AuthEvent struct {
+	UID   string `json:"uid"`
+	Email string `json:"email"`
+}
+
+// UserDeleted is the Firebase Auth user deletion hook, deployed as a separate function.
+// It erases the user's data the same way DELETE /account does.
+func UserDeleted(ctx context.Context, e AuthEvent) error {
+	logger := log.LoggerFromContext(ctx).With(slog.String(userIDLogField, log.UserID(e.UID)))
+	if e.UID == "" {
+		logger.Error("user deletion event without uid")
+		return nil
+	}
+	if err := account.Delete(ctx, e.UID); err != nil {
+		logger.Error("error while deleting user data", slog.String(ErrorMsgLogField, err.Error()))
+		// returning the error lets the trigger retry
+		return err
+	}
+	logger.Info("user data deleted")
+	return nil
+}
+
+func exportAccount(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	logger := log.LoggerFromContext(r.Context())
+	export, err := account.Export(r.Context(), token.UID)
+	if err != nil {
+		logger.Error("error while exporting user data", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	logger.Info("user data exported")
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="matchguru-export-%s.json"`, token.UID))
+	writeJSON(w, r, http.StatusOK, export)
+}
+
+// deleteAccount erases the user's data and the Firebase Auth user,
+// the data is deleted first so a failure leaves the user able to retry.
+func deleteAccount(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	logger := log.LoggerFromContext(r.Context())
+	ctx := context.WithoutCancel(r.Context())
+	if err := account.Delete(ctx, token.UID); err != nil {
+		logger.Error("error while deleting user data", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if err := auth.DeleteUser(ctx, token.UID); err != nil {
+		logger.Error("error while deleting auth user", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	logger.Info("account deleted")
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,115 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/store"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const firestoreUserCollection = "users"
+
+// Export collects everything stored about the user: the user document with the chats
+// and every subcollection of it, such as feedback, usage and quotas.
+func Export(ctx context.Context, userID string) (contract.AccountExport, error) {
+	export := contract.AccountExport{
+		UserID:      userID,
+		ExportedAt:  time.Now().UTC(),
+		Collections: map[string][]map[string]any{},
+	}
+	client, err := store.Client(ctx)
+	if err != nil {
+		return export, err
+	}
+	userRef := client.Collection(firestoreUserCollection).Doc(userID)
+
+	doc, err := userRef.Get(ctx)
+	switch {
+	case status.Code(err) == codes.NotFound:
+	case err != nil:
+		return export, err
+	default:
+		export.Profile = doc.Data()
+	}
+
+	err = forEachCollection(ctx, userRef, func(collection *firestore.CollectionRef) error {
+		docs, err := collection.Documents(ctx).GetAll()
+		if err != nil {
+			return err
+		}
+		for _, doc := range docs {
+			data := doc.Data()
+			data["id"] = doc.Ref.ID
+			export.Collections[collection.ID] = append(export.Collections[collection.ID], data)
+		}
+		return nil
+	})
+	return export, err
+}
+
+// Delete erases the user document with all its subcollections.
+func Delete(ctx context.Context, userID string) error {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return err
+	}
+	userRef := client.Collection(firestoreUserCollection).Doc(userID)
+
+	bw := client.BulkWriter(ctx)
+	var jobs []*firestore.BulkWriterJob
+	var errs []error
+	deleteDoc := func(ref *firestore.DocumentRef) {
+		job, err := bw.Delete(ref)
+		if err != nil {
+			errs = append(errs, err)
+			return
+		}
+		jobs = append(jobs, job)
+	}
+
+	err = forEachCollection(ctx, userRef, func(collection *firestore.CollectionRef) error {
+		refs, err := collection.DocumentRefs(ctx).GetAll()
+		if err != nil {
+			return err
+		}
+		for _, ref := range refs {
+			deleteDoc(ref)
+		}
+		return nil
+	})
+	if err != nil {
+		bw.End()
+		return err
+	}
+	deleteDoc(userRef)
+	bw.End()
+
+	for _, job := range jobs {
+		if _, err := job.Results(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func forEachCollection(ctx context.Context, doc *firestore.DocumentRef, fn func(*firestore.CollectionRef) error) error {
+	iter := doc.Collections(ctx)
+	for {
+		collection, err := iter.Next()
+		if err == iterator.Done {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := fn(collection); err != nil {
+			return err
+		}
+	}
+}
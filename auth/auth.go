@@ -1,27 +1,77 @@
 package auth
 
 import (
+	"context"
 	"net/http"
+	"sync"
 
 	firebase "firebase.google.com/go/v4"
 	"firebase.google.com/go/v4/auth"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
 )
 
-func Authenticate(req *http.Request) (*auth.Token, error) {
-	ctx := req.Context()
-	app, err := firebase.NewApp(ctx, nil)
+var tracer = otel.Tracer("github.com/klipach/matchguru/auth")
+
+var (
+	clientMu sync.Mutex
+	client   *auth.Client
+)
+
+func Authenticate(req *http.Request) (token *auth.Token, err error) {
+	ctx, span := tracer.Start(req.Context(), "auth.Authenticate")
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+	}()
+
+	jwtToken, err := bearerTokenFromRequest(req)
 	if err != nil {
 		return nil, err
 	}
 
-	client, err := app.Auth(ctx)
+	client, err := authClient(ctx)
 	if err != nil {
 		return nil, err
 	}
+	return client.VerifyIDToken(ctx, jwtToken)
+}
 
-	jwtToken, err := bearerTokenFromRequest(req)
+// authClient returns the Firebase Auth client shared by all requests.
+// The client caches Google public keys, so reusing it saves a round trip per request.
+func authClient(ctx context.Context) (*auth.Client, error) {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+
+	if client != nil {
+		return client, nil
+	}
+
+	ctx = context.WithoutCancel(ctx)
+	app, err := firebase.NewApp(ctx, nil)
 	if err != nil {
 		return nil, err
 	}
-	return client.VerifyIDToken(ctx, jwtToken)
+
+	c, err := app.Auth(ctx)
+	if err != nil {
+		return nil, err
+	}
+	client = c
+	return client, nil
+}
+
+// DeleteUser removes the Firebase Auth user, a user that doesn't exist anymore is not an error.
+func DeleteUser(ctx context.Context, uid string) error {
+	client, err := authClient(ctx)
+	if err != nil {
+		return err
+	}
+	if err := client.DeleteUser(ctx, uid); err != nil && !auth.IsUserNotFound(err) {
+		return err
+	}
+	return nil
 }
@@ -3,14 +3,20 @@ package matchguru
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
+	"math"
 	"net/http"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
 	"time"
 	_ "time/tzdata"
@@ -18,38 +24,146 @@ import (
 	"github.com/GoogleCloudPlatform/functions-framework-go/functions"
 	"github.com/klipach/matchguru/auth"
 	"github.com/klipach/matchguru/chat"
+	"github.com/klipach/matchguru/config"
 	"github.com/klipach/matchguru/contract"
 	"github.com/klipach/matchguru/filter"
 	"github.com/klipach/matchguru/fixture"
+	"github.com/klipach/matchguru/linkmiss"
+	"github.com/klipach/matchguru/locale"
 	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/preferences"
+	"github.com/klipach/matchguru/ratelimit"
+	"github.com/klipach/matchguru/sport"
+	"github.com/klipach/matchguru/sse"
+	"github.com/klipach/matchguru/telemetry"
+	"github.com/klipach/matchguru/usage"
 	"github.com/tmc/langchaingo/llms"
 	"github.com/tmc/langchaingo/llms/openai"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/text/language"
 )
 
 const (
-	ErrorMsgLogField = "errorMsg"
-	bodyLogField     = "body"
-	userIDLogField   = "userID"
-	chatIDLogField   = "chatID"
-	gameIDLogField   = "gameID"
-	timezaneLogField = "timezone"
+	ErrorMsgLogField      = "errorMsg"
+	bodyLogField          = "body"
+	userIDLogField        = "userID"
+	chatIDLogField        = "chatID"
+	gameIDLogField        = "gameID"
+	timezaneLogField      = "timezone"
+	planLogField          = "plan"
+	promptVersionLogField = "promptVersion"
+	sportLogField         = "sport"
+	languageLogField      = "language"
 
 	gcloudFuncSourceDir = "serverless_function_source_code"
-	openAIModel         = "gpt-4o-mini-search-preview"
-)
+	// set by the Cloud Functions runtime to the entry point name
+	functionTargetEnv = "FUNCTION_TARGET"
+	botFunctionTarget = "Bot"
 
-var (
-	openaiAPIKey = os.Getenv("OPENAI_API_KEY")
+	titleGenerationTimeout = 10 * time.Second
 )
 
+// bot answers chat messages, streaming the OpenAI response as SSE.
+// It is built once per instance and shared by all requests.
+type bot struct {
+	cfg *config.Config
+	// fixtures has a fetcher per sport with a fixture API
+	fixtures map[sport.Sport]fixture.Fetcher
+	// odds are the pre-match odds of football games, nil when disabled
+	odds    fixture.OddsFetcher
+	limiter *ratelimit.Limiter
+	prompts map[sport.Sport]*prompt
+
+	// OpenAI clients are built once per model
+	openAIClientsMu sync.Mutex
+	openAIClients   map[string]*openai.LLM
+}
+
+// prompt is the system prompt of a sport, the shared template rendered with the sport's profile.
+type prompt struct {
+	template *template.Template
+	profile  sportProfile
+	// version identifies the prompt revision answers are generated with
+	version string
+}
+
+const promptFile = "prompts/main.tmpl"
+
+// promptData is rendered into the system prompt templates.
+type promptData struct {
+	Sport           sportProfile
+	UserLocalTime   string
+	UserOffset      string
+	Fixture         *fixture.Fixture
+	BettingAnalysis bool
+	// Preferences are nil when the user set none
+	Preferences *preferences.Preferences
+	// Language is the English name of the language to answer in
+	Language string
+	// Refusals are the refusal templates in that language
+	Refusals []string
+	// Odds are nil without betting analysis or when the game has no pre-match odds
+	Odds *contract.Odds
+}
+
+var promptFuncs = template.FuncMap{
+	// percent formats a probability such as 0.4545 as 45.5%
+	"percent": func(p float64) string { return strconv.FormatFloat(p*100, 'f', 1, 64) + "%" },
+	"join":    strings.Join,
+	"upper":   strings.ToUpper,
+}
+
+// loadPrompts parses the prompt template once per sport, the version covers the template and the profile.
+func loadPrompts(path string) (map[sport.Sport]*prompt, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New(filepath.Base(path)).Funcs(promptFuncs).Parse(string(data))
+	if err != nil {
+		return nil, err
+	}
+	prompts := make(map[sport.Sport]*prompt, len(sport.All))
+	for _, s := range sport.All {
+		profile, ok := sportProfiles[s]
+		if !ok {
+			return nil, fmt.Errorf("no prompt profile for %s", s)
+		}
+		promptHash := sha256.Sum256([]byte(string(data) + fmt.Sprintf("%+v", profile)))
+		prompts[s] = &prompt{template: tmpl, profile: profile, version: hex.EncodeToString(promptHash[:6])}
+	}
+	return prompts, nil
+}
+
+func newBot(cfg *config.Config) (*bot, error) {
+	prompts, err := loadPrompts(promptFile)
+	if err != nil {
+		return nil, err
+	}
+	football := fixture.NewClient(cfg.SportmonksAPIKey, cfg.SportmonksBaseURL)
+	return &bot{
+		cfg: cfg,
+		fixtures: map[sport.Sport]fixture.Fetcher{
+			sport.Football: football,
+			sport.Cricket:  fixture.NewCricketClient(cfg.SportmonksAPIKey, cfg.CricketBaseURL),
+		},
+		odds:          oddsFetcher(cfg.OddsSource, football),
+		limiter:       ratelimit.NewLimiter(ratelimit.FirestoreStore{}),
+		prompts:       prompts,
+		openAIClients: map[string]*openai.LLM{},
+	}, nil
+}
+
 // modifyingRoundTripper removes the "temperature" field and adds "web_search_options"
 type modifyingRoundTripper struct {
 	rt http.RoundTripper
 }
 
-func SetupStreamingFunction(w io.Writer, flusher http.Flusher) func(ctx context.Context, chunk []byte) error {
+// SetupStreamingFunction returns a streaming function sending the cleaned chunks to the client,
+// the streamed text is collected in streamed, as the client sees it.
+// ilf keeps the internal link names without mapping for the caller.
+func SetupStreamingFunction(sw *sse.Writer, ilf *filter.InternalLinkFilter, streamed *strings.Builder) func(ctx context.Context, chunk []byte) error {
 	// persistent buffer per SetupStreamingFunction
-	ilf := &filter.InternalLinkFilter{}
 	elf := &filter.ExternalLinkFilter{}
 
 	return func(ctx context.Context, chunk []byte) error {
@@ -63,20 +177,42 @@ func SetupStreamingFunction(w io.Writer, flusher http.Flusher) func(ctx context.
 		if cleanedChunk == "" {
 			return nil
 		}
-		msg := contract.BotResponse{Response: cleanedChunk}
-		jsonData, err := json.Marshal(msg)
-		if err != nil {
-			return err
-		}
-		sseData := fmt.Sprintf("data: %s\n\n", jsonData)
-		if _, err := w.Write([]byte(sseData)); err != nil {
-			return err
-		}
-		flusher.Flush()
-		return nil
+		streamed.WriteString(cleanedChunk)
+		return sw.Data(contract.BotResponse{Response: cleanedChunk})
 	}
 }
 
+// openAIClient returns the shared client for the given model, creating it on first use.
+func (b *bot) openAIClient(model string) (*openai.LLM, error) {
+	b.openAIClientsMu.Lock()
+	defer b.openAIClientsMu.Unlock()
+
+	if client, ok := b.openAIClients[model]; ok {
+		return client, nil
+	}
+	var transport http.RoundTripper = &loggingRoundTripper{
+		rt: telemetry.Transport(http.DefaultTransport),
+	}
+	// web search options are rejected by models without web search
+	if isSearchModel(model) {
+		transport = &modifyingRoundTripper{rt: transport}
+	}
+	client, err := openai.New(
+		openai.WithModel(model),
+		openai.WithToken(b.cfg.OpenAIAPIKey),
+		openai.WithHTTPClient(&http.Client{Transport: transport}),
+	)
+	if err != nil {
+		return nil, err
+	}
+	b.openAIClients[model] = client
+	return client, nil
+}
+
+func isSearchModel(model string) bool {
+	return strings.Contains(model, "-search-")
+}
+
 func (mrt *modifyingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
 	var bodyBytes []byte
 	if req.Body != nil {
@@ -91,6 +227,10 @@ func (mrt *modifyingRoundTripper) RoundTrip(req *http.Request) (*http.Response,
 		delete(jsonData, "temperature")
 		// add "web_search_options" field with an empty object
 		jsonData["web_search_options"] = map[string]any{}
+		// make sure the last streamed chunk carries token usage, it is needed for cost accounting
+		if stream, _ := jsonData["stream"].(bool); stream {
+			jsonData["stream_options"] = map[string]any{"include_usage": true}
+		}
 		if mBody, err := json.Marshal(jsonData); err == nil {
 			modifiedBody = mBody
 		} else {
@@ -126,14 +266,50 @@ func (lrt *loggingRoundTripper) RoundTrip(req *http.Request) (*http.Response, er
 
 	logger.Info("openAI request",
 		slog.String("url", req.URL.String()),
-		slog.String(bodyLogField, string(bodyBytes)),
+		slog.String(bodyLogField, log.Body(req.Context(), string(bodyBytes))),
 	)
 	return lrt.rt.RoundTrip(req)
 }
 
 func init() {
-	functions.HTTP("Bot", Bot)
 	fixDir()
+
+	// cmd/server loads the config and builds its own handler,
+	// so the function handler is only built when running as the Bot or Previews Cloud Function,
+	// other entry points such as UserDeleted don't need the bot config
+	target := os.Getenv(functionTargetEnv)
+	if target != botFunctionTarget && target != previewsFunctionTarget {
+		return
+	}
+	ctx := context.Background()
+	logger := log.LoggerFromContext(ctx)
+	cfg, err := config.Load(ctx)
+	if err != nil {
+		// fail the cold start instead of serving requests with a broken config
+		logger.Error("error while loading config", slog.String(ErrorMsgLogField, err.Error()))
+		panic(err)
+	}
+	// the function instance has no shutdown hook, spans and metrics are exported in the background
+	if _, err := telemetry.Setup(ctx, cfg.ProjectID, cfg.Tracing); err != nil {
+		logger.Error("error while setting up tracing", slog.String(ErrorMsgLogField, err.Error()))
+		panic(err)
+	}
+	// metrics can't be scraped from a function, so Prometheus is only useful with cmd/server
+	if _, _, err := telemetry.SetupMetrics(ctx, cfg.ProjectID, cfg.Metrics); err != nil {
+		logger.Error("error while setting up metrics", slog.String(ErrorMsgLogField, err.Error()))
+		panic(err)
+	}
+	var handler http.Handler
+	if target == previewsFunctionTarget {
+		handler, err = NewPreviewsHandler(ctx, cfg)
+	} else {
+		handler, err = NewHandler(cfg)
+	}
+	if err != nil {
+		logger.Error("error while creating handler", slog.String(ErrorMsgLogField, err.Error()))
+		panic(err)
+	}
+	functions.HTTP(target, handler.ServeHTTP)
 }
 
 // in GCP Functions, source code is placed in a directory named "serverless_function_source_code"
@@ -145,24 +321,99 @@ func fixDir() {
 	}
 }
 
-func Bot(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	logger := log.LoggerFromContext(ctx)
-	logger.Info("bot function called")
+// answerMode selects how the prompt is built from the chat history and who stores the answer.
+type answerMode int
+
+const (
+	// answerNew answers a new user message, the client stores the exchange
+	answerNew answerMode = iota
+	// answerRegenerate generates the last answer again
+	answerRegenerate
+	// answerEdit replaces the last user message and answers it
+	answerEdit
+)
+
+func (m answerMode) String() string {
+	switch m {
+	case answerRegenerate:
+		return "regenerate"
+	case answerEdit:
+		return "edit"
+	default:
+		return "new"
+	}
+}
+
+// messages returns the chat messages the answer is generated from, an error when the chat can't be rewound.
+func (m answerMode) messages(history chat.History, message string) ([]llms.MessageContent, error) {
+	switch m {
+	case answerRegenerate:
+		return history.WithoutLastAnswer()
+	case answerEdit:
+		messages, err := history.WithoutLastQuestion()
+		if err != nil {
+			return nil, err
+		}
+		return append(messages, llms.TextParts(llms.ChatMessageTypeHuman, message)), nil
+	default:
+		// append user message at the end of the messages history
+		return append(history.Messages, llms.TextParts(llms.ChatMessageTypeHuman, message)), nil
+	}
+}
 
+// answerIndex is the index the answer gets in the chat messages, the one feedback addresses it by.
+func (m answerMode) answerIndex(history chat.History) int {
+	switch m {
+	case answerRegenerate:
+		return history.Length - 1
+	case answerEdit:
+		// the question is replaced in place and the answer follows it
+		if n := len(history.Messages); n > 0 && history.Messages[n-1].Role == llms.ChatMessageTypeAI {
+			return history.Length - 1
+		}
+		return history.Length
+	default:
+		// the client appends the question and the answer
+		return history.Length + 1
+	}
+}
+
+func (b *bot) ServeHTTP(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
-		logger.Error("invalid method: " + r.Method)
+		log.LoggerFromContext(r.Context()).Error("invalid method: " + r.Method)
 		http.Error(w, "Method Not Implemented", http.StatusNotImplemented)
 		return
 	}
+	b.answer(w, r, answerNew)
+}
 
-	token, err := auth.Authenticate(r)
+// regenerate streams a new version of the chat's last answer, the previous one is kept as an alternate.
+func (b *bot) regenerate(w http.ResponseWriter, r *http.Request) {
+	b.answer(w, r, answerRegenerate)
+}
+
+// edit replaces the chat's last user message and streams the answer to it,
+// the previous question and answer are kept as alternates.
+func (b *bot) edit(w http.ResponseWriter, r *http.Request) {
+	b.answer(w, r, answerEdit)
+}
+
+func (b *bot) answer(w http.ResponseWriter, r *http.Request, mode answerMode) {
+	ctx := r.Context()
+	logger := log.LoggerFromContext(ctx)
+	logger.Info("bot function called", slog.String("mode", mode.String()))
+
+	status := telemetry.StatusError
+	defer func() {
+		telemetry.RecordRequest(ctx, mode.String(), status)
+	}()
+
+	sw, err := sse.NewWriter(w)
 	if err != nil {
-		logger.Error("error while authenticating", slog.String(ErrorMsgLogField, err.Error()))
-		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		logger.Error("streaming unsupported!")
+		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
 		return
 	}
-	logger = logger.With(slog.String(userIDLogField, token.UID))
 
 	var msg contract.BotRequest
 	data, err := io.ReadAll(r.Body)
@@ -171,124 +422,385 @@ func Bot(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
 		return
 	}
-	logger.Info("incoming request", slog.String(bodyLogField, string(data)))
-
 	if err := json.Unmarshal(data, &msg); err != nil {
 		logger.Error("error while decoding request", slog.String(ErrorMsgLogField, err.Error()))
+		status = telemetry.StatusBadRequest
 		http.Error(w, "Bad Request", http.StatusBadRequest)
 		return
 	}
+	if mode != answerNew {
+		// regenerate and edit address the chat by path
+		if msg.ChatID, err = strconv.Atoi(r.PathValue("chatID")); err != nil {
+			status = telemetry.StatusBadRequest
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+	if mode == answerEdit && strings.TrimSpace(msg.Message) == "" {
+		status = telemetry.StatusBadRequest
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	sp, err := sport.Parse(msg.Sport)
+	if err != nil {
+		logger.Error("error while parsing sport", slog.String(ErrorMsgLogField, err.Error()))
+		status = telemetry.StatusBadRequest
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	sportPrompt := b.prompts[sp]
+	lang, err := requestLanguage(r, msg)
+	if err != nil {
+		logger.Error("error while parsing language", slog.String(ErrorMsgLogField, err.Error()))
+		status = telemetry.StatusBadRequest
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	localized := locale.For(lang)
+
+	loc, err := time.LoadLocation(msg.Timezone)
+	if err != nil {
+		logger.Error("error while loading location", slog.String(ErrorMsgLogField, err.Error()))
+		loc = time.UTC
+	}
+
+	token, err := auth.Authenticate(r)
+	if err != nil {
+		logger.Error("error while authenticating", slog.String(ErrorMsgLogField, err.Error()))
+		status = telemetry.StatusUnauthorized
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	entitlements := b.cfg.Plans.FromClaims(token.Claims)
 	logger = logger.With(
+		slog.String(userIDLogField, log.UserID(token.UID)),
+		slog.String(planLogField, entitlements.Plan),
+		slog.String(sportLogField, string(sp)),
+		slog.String(languageLogField, lang.String()),
+		slog.String(promptVersionLogField, sportPrompt.version),
 		slog.Int(chatIDLogField, msg.ChatID),
 		slog.Int(gameIDLogField, msg.GameID),
 		slog.String(timezaneLogField, msg.Timezone),
 	)
-	ctx = log.WithLogger(ctx, logger)
+	// debug users and a sample of requests are logged with full bodies
+	ctx = log.WithBodySampling(log.WithLogger(ctx, logger), token.UID)
+	logger.Info("incoming request", slog.Bool("verbose", log.Verbose(ctx)), slog.String(bodyLogField, log.Body(ctx, string(data))))
 
-	loc, err := time.LoadLocation(msg.Timezone)
+	decision, err := b.limiter.Allow(ctx, token.UID, entitlements.RateLimits)
 	if err != nil {
-		logger.Error("error while loading location", slog.String(ErrorMsgLogField, err.Error()))
+		// quota store unavailability should not take the bot down, so fail open
+		logger.Error("error while checking rate limit", slog.String(ErrorMsgLogField, err.Error()))
+	} else if !decision.Allowed {
+		logger.Warn("rate limited", slog.String("reason", decision.Reason), slog.Duration("retryAfter", decision.RetryAfter))
+		status = decision.Reason
+		rejectRateLimited(w, sw, decision, localized)
+		return
 	}
-	userLocalTime := time.Now().In(loc).Format(time.RFC1123Z)
-	userOffset := time.Now().In(loc).Format("-07:00")
+
+	// SportMonks is only called for authenticated requests within their limits,
+	// the fixture is fetched while the chat history and the preferences load
+	loadCtx, cancelLoad := context.WithCancel(ctx)
+	defer cancelLoad()
+	g, gctx := errgroup.WithContext(loadCtx)
 	f := &fixture.Fixture{}
 	if msg.GameID != 0 {
-		f, err = fixture.Fetch(ctx, msg.GameID)
+		g.Go(func() error {
+			if fetched := b.fetchFixture(gctx, sp, msg.GameID, loc); fetched != nil {
+				// each sets its own field of the fixture, so they run side by side
+				var wg sync.WaitGroup
+				wg.Go(func() { b.fetchLive(gctx, sp, fetched) })
+				// odds are only used by plans with betting analysis
+				if entitlements.BettingAnalysis {
+					wg.Go(func() { b.fetchOdds(gctx, sp, fetched) })
+				}
+				wg.Wait()
+				f = fetched
+			}
+			return nil
+		})
+	}
+
+	var history chat.History
+	g.Go(func() error {
+		hctx, span := tracer.Start(gctx, "bot.history")
+		hctx, cancel := context.WithTimeout(log.WithLogger(hctx, logger), b.cfg.HistoryLoadTimeout.Duration)
+		defer cancel()
+		var err error
+		history, err = chat.LoadHistory(hctx, token.UID, msg.ChatID, entitlements.HistoryDepth)
+		endSpan(span, err)
+		return err
+	})
+
+	var prefs *preferences.Preferences
+	g.Go(func() error {
+		pctx, span := tracer.Start(gctx, "bot.preferences")
+		pctx, cancel := context.WithTimeout(log.WithLogger(pctx, logger), b.cfg.HistoryLoadTimeout.Duration)
+		defer cancel()
+		p, err := preferences.Load(pctx, token.UID)
+		endSpan(span, err)
+		// the answer is only less tailored without preferences
 		if err != nil {
-			logger.Error("error while fetching fixture", slog.String(ErrorMsgLogField, err.Error()))
-		} else {
-			f.StartingAt = f.StartingAt.In(loc)
-			logger.Info("fixture fetched", slog.Any("fixture", f))
+			logger.Error("error while loading preferences", slog.String(ErrorMsgLogField, err.Error()))
+			return nil
+		}
+		if !p.IsZero() {
+			prefs = &p
+		}
+		return nil
+	})
+
+	// let the client know the request is accepted while the context is being prepared
+	sw.Start()
+	if err := sw.Event(contract.EventThinking, contract.BotStatus{Status: "thinking"}); err != nil {
+		logger.Error("error while sending thinking event", slog.String(ErrorMsgLogField, err.Error()))
+		// the client is gone, the loads still running are stopped before returning
+		cancelLoad()
+		_ = g.Wait()
+		return
+	}
+
+	// headers are already sent, so from now on errors are reported as SSE error events
+	streamError := func() {
+		if err := sw.Event(contract.EventError, contract.BotError{Error: "Internal Server Error", Message: localized.InternalError}); err != nil {
+			logger.Error("error while sending error event", slog.String(ErrorMsgLogField, err.Error()))
 		}
 	}
 
-	messages, err := chat.LoadHistory(ctx, token.UID, msg.ChatID)
-	if err != nil {
+	if err := g.Wait(); err != nil {
 		logger.Error("error while loading chat history", slog.String(ErrorMsgLogField, err.Error()))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		streamError()
 		return
 	}
+	// the language set in the app settings wins over the one of the device
+	if msg.Language == "" && prefs != nil && prefs.Language != "" {
+		if lang, err = locale.Parse(prefs.Language); err != nil {
+			logger.Error("error while parsing preferred language", slog.String(ErrorMsgLogField, err.Error()))
+			lang = locale.Default
+		}
+		localized = locale.For(lang)
+	}
 
-	// append user message at the end of the messages history
-	messages = append(messages, llms.TextParts(llms.ChatMessageTypeHuman, msg.Message))
-
-	openAIClient, err := openai.New(
-		openai.WithModel(openAIModel),
-		openai.WithToken(openaiAPIKey),
-		openai.WithHTTPClient(
-			&http.Client{
-				Transport: &modifyingRoundTripper{
-					rt: &loggingRoundTripper{
-						rt: http.DefaultTransport,
-					},
-				},
-			},
-		),
-	)
-	if err != nil {
-		logger.Error("error while creating openAI client", slog.String(ErrorMsgLogField, err.Error()))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+	messages, rewindErr := mode.messages(history, msg.Message)
+	if rewindErr != nil {
+		logger.Warn("chat can't be rewound", slog.String(ErrorMsgLogField, rewindErr.Error()))
+		status = telemetry.StatusConflict
+		if err := sw.Event(contract.EventError, contract.BotError{Error: rewindErr.Error(), Message: localized.Conflict, Code: contract.ErrorCodeConflict}); err != nil {
+			logger.Error("error while sending error event", slog.String(ErrorMsgLogField, err.Error()))
+		}
 		return
 	}
 
-	mainPrompt, err := template.New("main.tmpl").ParseFiles("prompts/main.tmpl")
+	var oddsData *contract.Odds
+	if f.Odds != nil {
+		oddsData = oddsEvent(f.ID, f.Odds, prefs)
+		if err := sw.Event(contract.EventOdds, oddsData); err != nil {
+			logger.Error("error while sending odds event", slog.String(ErrorMsgLogField, err.Error()))
+		}
+	}
+
+	llm, err := b.openAIClient(entitlements.Model)
 	if err != nil {
-		logger.Error("error while parsing mainPrompt", slog.String(ErrorMsgLogField, err.Error()))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		logger.Error("error while creating openAI client", slog.String(ErrorMsgLogField, err.Error()))
+		streamError()
 		return
 	}
 
+	_, promptSpan := tracer.Start(ctx, "bot.prompt")
 	var mainPromptStr strings.Builder
-	err = mainPrompt.Execute(
+	err = sportPrompt.template.Execute(
 		&mainPromptStr,
-		struct {
-			UserLocalTime string
-			UserOffset    string
-			Fixture       *fixture.Fixture
-		}{
-			UserLocalTime: userLocalTime,
-			UserOffset:    userOffset,
-			Fixture:       f,
+		promptData{
+			Sport:           sportPrompt.profile,
+			UserLocalTime:   time.Now().In(loc).Format(time.RFC1123Z),
+			UserOffset:      time.Now().In(loc).Format("-07:00"),
+			Fixture:         f,
+			BettingAnalysis: entitlements.BettingAnalysis,
+			Preferences:     prefs,
+			Language:        locale.Name(lang),
+			Refusals:        localized.RefusalsFor(sp),
+			Odds:            oddsData,
 		},
 	)
+	endSpan(promptSpan, err)
 	if err != nil {
-		logger.Error("error while executing mainPrompt", slog.String(ErrorMsgLogField, err.Error()))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
-		return
-	}
-
-	// set SSE headers for streaming
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	flusher, ok := w.(http.Flusher)
-	if !ok {
-		logger.Error("streaming unsupported!")
-		http.Error(w, "Streaming unsupported!", http.StatusInternalServerError)
+		logger.Error("error while executing prompt", slog.String(ErrorMsgLogField, err.Error()))
+		streamError()
 		return
 	}
 
-	resp, err := openAIClient.GenerateContent(
-		ctx,
+	var streamed strings.Builder
+	ilf := filter.NewInternalLinkFilter(filter.DictionaryFor(sp), lang)
+	streamingFunc := SetupStreamingFunction(sw, ilf, &streamed)
+	genCtx, gen := startGeneration(ctx, entitlements.Model)
+	resp, err := llm.GenerateContent(
+		genCtx,
 		append(
 			[]llms.MessageContent{
 				llms.TextParts(llms.ChatMessageTypeSystem, mainPromptStr.String()),
 			},
 			messages...,
 		),
-		llms.WithStreamingFunc(SetupStreamingFunction(w, flusher)),
-		llms.WithMaxTokens(1000),
+		llms.WithStreamingFunc(func(ctx context.Context, chunk []byte) error {
+			gen.chunk()
+			return streamingFunc(ctx, chunk)
+		}),
+		llms.WithMaxTokens(entitlements.MaxTokens),
 	)
+	gen.end(err)
 
 	if err != nil {
 		logger.Error("ChatCompletion error", slog.String(ErrorMsgLogField, err.Error()))
-		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		streamError()
 		return
 	}
 
 	if len(resp.Choices) > 0 {
-		logger.Info("openAI response", slog.String("response", resp.Choices[0].Content))
+		logger.Info("openAI response", slog.String("response", log.Body(ctx, resp.Choices[0].Content)))
 	} else {
 		logger.Error("no openAI response")
 	}
+	if filter.IsRefusal(streamed.String()) {
+		logger.Info("off-topic question refused")
+		telemetry.RecordRefusal(ctx, entitlements.Model)
+	}
+
+	// the client may be gone already, the usage must be recorded anyway
+	usageEntry := usage.NewEntry(entitlements.Model, msg.ChatID, resp, time.Now())
+	usageEntry.MessageIndex = mode.answerIndex(history)
+	usageEntry.PromptVersion = sportPrompt.version
+	b.recordUsage(context.WithoutCancel(ctx), token.UID, usageEntry)
+	if err := b.limiter.Record(context.WithoutCancel(ctx), token.UID, usageEntry.TotalTokens); err != nil {
+		logger.Error("error while recording rate limit usage", slog.String(ErrorMsgLogField, err.Error()))
+	}
+	// mined by cmd/linkmine to grow the team and league dictionaries, it searches the football API only
+	if sp == sport.Football {
+		if err := linkmiss.Record(context.WithoutCancel(ctx), ilf.Unresolved()); err != nil {
+			logger.Error("error while recording unresolved links", slog.String(ErrorMsgLogField, err.Error()))
+		}
+	}
+
+	// the answer is stored as streamed, the same way the client stores new answers
+	switch mode {
+	case answerRegenerate:
+		err = chat.ReplaceLastAnswer(context.WithoutCancel(ctx), token.UID, msg.ChatID, streamed.String())
+	case answerEdit:
+		err = chat.EditLastQuestion(context.WithoutCancel(ctx), token.UID, msg.ChatID, msg.Message, streamed.String())
+	}
+	if err != nil {
+		logger.Error("error while storing answer", slog.String(ErrorMsgLogField, err.Error()))
+		streamError()
+		return
+	}
+	status = telemetry.StatusOK
+
+	// the first exchange of a chat gives enough context to name it
+	if mode == answerNew && len(history.Messages) == 0 && history.Title == "" && len(resp.Choices) > 0 {
+		b.generateTitle(ctx, sw, token.UID, msg.ChatID, sportPrompt.profile.Name, locale.Name(lang), msg.Message, resp.Choices[0].Content)
+	}
+
+	if msg.LiveUpdates && f.Live != nil {
+		b.pushLiveUpdates(ctx, sw, sp, msg.GameID, f.Live)
+	}
+}
+
+// recordUsage saves the tokens and cost of the OpenAI response to the user's usage ledger.
+func (b *bot) recordUsage(ctx context.Context, userID string, entry usage.Entry) {
+	logger := log.LoggerFromContext(ctx)
+	telemetry.RecordTokens(ctx, entry.Model, entry.PromptTokens, entry.CompletionTokens)
+	logger.Info("openAI usage",
+		slog.String("model", entry.Model),
+		slog.Int("promptTokens", entry.PromptTokens),
+		slog.Int("completionTokens", entry.CompletionTokens),
+		slog.Float64("costUSD", entry.CostUSD),
+	)
+	if err := usage.Save(ctx, userID, entry); err != nil {
+		logger.Error("error while saving usage", slog.String(ErrorMsgLogField, err.Error()))
+	}
+}
+
+// generateTitle names the chat with a cheap model call, stores the title and sends it to the client.
+// A chat without title is not worth failing the answer for, so errors are only logged.
+func (b *bot) generateTitle(ctx context.Context, sw *sse.Writer, userID string, chatID int, sportName, language, userMessage, answer string) {
+	logger := log.LoggerFromContext(ctx)
+	ctx, cancel := context.WithTimeout(ctx, titleGenerationTimeout)
+	defer cancel()
+
+	llm, err := b.openAIClient(b.cfg.TitleModel)
+	if err != nil {
+		logger.Error("error while creating openAI client", slog.String(ErrorMsgLogField, err.Error()))
+		return
+	}
+	title, resp, err := chat.GenerateTitle(ctx, llm, sportName, language, userMessage, answer)
+	if err != nil {
+		logger.Error("error while generating chat title", slog.String(ErrorMsgLogField, err.Error()))
+		return
+	}
+	b.recordUsage(context.WithoutCancel(ctx), userID, usage.NewEntry(b.cfg.TitleModel, chatID, resp, time.Now()))
+	if title == "" {
+		logger.Warn("empty chat title generated")
+		return
+	}
+
+	set, err := chat.SetTitle(ctx, userID, chatID, title)
+	if errors.Is(err, chat.ErrChatNotFound) {
+		logger.Warn("chat not found, title is not stored")
+	} else if err != nil {
+		logger.Error("error while storing chat title", slog.String(ErrorMsgLogField, err.Error()))
+		return
+	} else if !set {
+		// titled by the user in the meantime
+		return
+	}
+	logger.Info("chat title generated", slog.String("title", log.MaskPII(title)))
+
+	if err := sw.Event(contract.EventTitle, contract.ChatTitle{ChatID: chatID, Title: title}); err != nil {
+		logger.Error("error while sending title event", slog.String(ErrorMsgLogField, err.Error()))
+	}
+}
+
+func rejectRateLimited(w http.ResponseWriter, sw *sse.Writer, decision ratelimit.Decision, messages *locale.Messages) {
+	retryAfter := int(math.Ceil(decision.RetryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	sw.StartWithStatus(http.StatusTooManyRequests)
+	_ = sw.Event(contract.EventError, contract.BotError{
+		Error:      "Too Many Requests",
+		Message:    messages.TooManyRequests,
+		Code:       decision.Reason,
+		RetryAfter: retryAfter,
+	})
+}
+
+// requestLanguage is the language asked for by the request, else the preferred language of the device,
+// undetermined when neither is set.
+func requestLanguage(r *http.Request, msg contract.BotRequest) (language.Tag, error) {
+	if msg.Language != "" {
+		return locale.Parse(msg.Language)
+	}
+	tag, _ := locale.FromAcceptLanguage(r.Header.Get("Accept-Language"))
+	return tag, nil
+}
+
+// fetchFixture fetches the game the chat is about from the API of the sport, bounded by the configured timeout.
+// The fixture only enriches the prompt, so on failure nil is returned and the bot answers without it.
+func (b *bot) fetchFixture(ctx context.Context, sp sport.Sport, gameID int, loc *time.Location) *fixture.Fixture {
+	logger := log.LoggerFromContext(ctx)
+	fetcher, ok := b.fixtures[sp]
+	if !ok {
+		logger.Warn("no fixture API for sport", slog.String(sportLogField, string(sp)), slog.Int(gameIDLogField, gameID))
+		return nil
+	}
+	ctx, span := tracer.Start(ctx, "bot.fixture")
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.FixtureFetchTimeout.Duration)
+	defer cancel()
+
+	f, err := fetcher.Fetch(ctx, gameID)
+	endSpan(span, err)
+	if err != nil {
+		telemetry.RecordFixtureError(ctx)
+		logger.Error("error while fetching fixture", slog.Int(gameIDLogField, gameID), slog.String(ErrorMsgLogField, err.Error()))
+		return nil
+	}
+	f.StartingAt = f.StartingAt.In(loc)
+	logger.Info("fixture fetched", slog.Any("fixture", f))
+	return f
 }
@@ -0,0 +1,111 @@
+package matchguru
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/klipach/matchguru/chat"
+	"github.com/klipach/matchguru/fixture"
+	"github.com/klipach/matchguru/sport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestAnswerModeMessages(t *testing.T) {
+	question := llms.TextParts(llms.ChatMessageTypeHuman, "who will win?")
+	answer := llms.TextParts(llms.ChatMessageTypeAI, "Arsenal")
+	next := llms.TextParts(llms.ChatMessageTypeHuman, "and the score?")
+
+	tests := []struct {
+		name     string
+		mode     answerMode
+		history  []llms.MessageContent
+		expected []llms.MessageContent
+		err      error
+	}{
+		{"new message", answerNew, []llms.MessageContent{question, answer}, []llms.MessageContent{question, answer, next}, nil},
+		{"new message in empty chat", answerNew, nil, []llms.MessageContent{next}, nil},
+		{"regenerate", answerRegenerate, []llms.MessageContent{question, answer}, []llms.MessageContent{question}, nil},
+		{"regenerate without answer", answerRegenerate, []llms.MessageContent{question}, nil, chat.ErrNoAnswer},
+		{"edit", answerEdit, []llms.MessageContent{question, answer}, []llms.MessageContent{next}, nil},
+		{"edit without question", answerEdit, nil, nil, chat.ErrNoQuestion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := tt.mode.messages(chat.History{Messages: tt.history}, "and the score?")
+			if tt.err != nil {
+				assert.ErrorIs(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, messages)
+		})
+	}
+}
+
+func TestAnswerModeAnswerIndex(t *testing.T) {
+	question := llms.TextParts(llms.ChatMessageTypeHuman, "who will win?")
+	answer := llms.TextParts(llms.ChatMessageTypeAI, "Arsenal")
+
+	tests := []struct {
+		name     string
+		mode     answerMode
+		history  chat.History
+		expected int
+	}{
+		{"new message in empty chat", answerNew, chat.History{}, 1},
+		{"new message", answerNew, chat.History{Messages: []llms.MessageContent{question, answer}, Length: 2}, 3},
+		{"new message beyond history depth", answerNew, chat.History{Messages: []llms.MessageContent{question, answer}, Length: 10}, 11},
+		{"regenerate", answerRegenerate, chat.History{Messages: []llms.MessageContent{question, answer}, Length: 4}, 3},
+		{"edit answered question", answerEdit, chat.History{Messages: []llms.MessageContent{question, answer}, Length: 4}, 3},
+		{"edit unanswered question", answerEdit, chat.History{Messages: []llms.MessageContent{answer, question}, Length: 3}, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.mode.answerIndex(tt.history))
+		})
+	}
+}
+
+func TestLoadPrompts(t *testing.T) {
+	prompts, err := loadPrompts(promptFile)
+	require.NoError(t, err)
+	require.Len(t, prompts, len(sport.All))
+	assert.NotEqual(t, prompts[sport.Football].version, prompts[sport.Cricket].version)
+
+	tests := []struct {
+		sport    sport.Sport
+		fixture  *fixture.Fixture
+		contains []string
+		excludes []string
+	}{
+		{
+			sport:    sport.Football,
+			fixture:  &fixture.Fixture{Name: "Arsenal vs Chelsea", HomeTeam: fixture.Team{Name: "Arsenal", Country: "England"}},
+			contains: []string{"SofaScore", "SOCCER/FOOTBALL ONLY", "{Бернлі|Burnley}", `"Arsenal" from England`, "Current Soccer Game Context"},
+			excludes: []string{"Cricbuzz", "- **Format**"},
+		},
+		{
+			sport:    sport.Cricket,
+			fixture:  &fixture.Fixture{Name: "India vs Australia", Format: "T20", HomeTeam: fixture.Team{Name: "India"}},
+			contains: []string{"Cricbuzz", "CRICKET ONLY", "{Індія|India}", "- **Format**: T20", "Current Cricket Game Context"},
+			excludes: []string{"SofaScore", "soccer", `"India" from`, "Country League"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.sport), func(t *testing.T) {
+			p := prompts[tt.sport]
+			var sb strings.Builder
+			require.NoError(t, p.template.Execute(&sb, promptData{Sport: p.profile, Fixture: tt.fixture, Language: "English"}))
+			rendered := sb.String()
+			assert.NotContains(t, rendered, "<no value>")
+			for _, s := range tt.contains {
+				assert.Contains(t, rendered, s)
+			}
+			for _, s := range tt.excludes {
+				assert.NotContains(t, rendered, s)
+			}
+		})
+	}
+}
@@ -0,0 +1,309 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"maps"
+	"reflect"
+	"slices"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const chatsField = "chats"
+
+var (
+	ErrChatNotFound    = errors.New("chat not found")
+	ErrMessageNotFound = errors.New("message not found")
+)
+
+// ListChats returns the user's chats, most recently updated first.
+func ListChats(ctx context.Context, userID string) ([]contract.Chat, error) {
+	user, err := loadUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	chats := make([]contract.Chat, 0, len(user.Chats))
+	for _, c := range user.Chats {
+		chats = append(chats, toContractChat(c))
+	}
+	slices.SortStableFunc(chats, func(a, b contract.Chat) int {
+		return b.UpdatedAt.Compare(a.UpdatedAt)
+	})
+	return chats, nil
+}
+
+// CreateChat adds an empty chat with a server assigned ID.
+func CreateChat(ctx context.Context, userID, title string) (contract.Chat, error) {
+	var created *firestoreChat
+	err := updateUser(ctx, userID, func(user *firestoreUser) error {
+		now := time.Now().UTC()
+		created = &firestoreChat{
+			ChatID:    nextChatID(user.Chats),
+			Title:     title,
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+		user.Chats = append(user.Chats, created)
+		return nil
+	})
+	if err != nil {
+		return contract.Chat{}, err
+	}
+	return toContractChat(created), nil
+}
+
+// RenameChat sets the chat title.
+func RenameChat(ctx context.Context, userID string, chatID int, title string) (contract.Chat, error) {
+	var renamed *firestoreChat
+	err := updateUser(ctx, userID, func(user *firestoreUser) error {
+		renamed = findChat(user.Chats, chatID)
+		if renamed == nil {
+			return ErrChatNotFound
+		}
+		renamed.Title = title
+		renamed.UpdatedAt = time.Now().UTC()
+		return nil
+	})
+	if err != nil {
+		return contract.Chat{}, err
+	}
+	return toContractChat(renamed), nil
+}
+
+// DeleteChat removes the chat with all its messages.
+func DeleteChat(ctx context.Context, userID string, chatID int) error {
+	return updateUser(ctx, userID, func(user *firestoreUser) error {
+		n := len(user.Chats)
+		user.Chats = slices.DeleteFunc(user.Chats, func(c *firestoreChat) bool {
+			return c.ChatID == chatID
+		})
+		if len(user.Chats) == n {
+			return ErrChatNotFound
+		}
+		return nil
+	})
+}
+
+// Messages returns up to limit chat messages starting at offset, in chronological order,
+// and the total number of messages in the chat.
+func Messages(ctx context.Context, userID string, chatID, offset, limit int) ([]contract.ChatMessage, int, error) {
+	user, err := loadUser(ctx, userID)
+	if err != nil {
+		return nil, 0, err
+	}
+	c := findChat(user.Chats, chatID)
+	if c == nil {
+		return nil, 0, ErrChatNotFound
+	}
+	page := pageMessages(c.Messages, offset, limit)
+	messages := make([]contract.ChatMessage, 0, len(page))
+	for _, m := range page {
+		messages = append(messages, toContractMessage(m))
+	}
+	return messages, len(c.Messages), nil
+}
+
+// Answer returns the bot message at index and the user message it answers.
+func Answer(ctx context.Context, userID string, chatID, index int) (question, answer string, err error) {
+	user, err := loadUser(ctx, userID)
+	if err != nil {
+		return "", "", err
+	}
+	c := findChat(user.Chats, chatID)
+	if c == nil {
+		return "", "", ErrChatNotFound
+	}
+	return answerAt(c.Messages, index)
+}
+
+// loadUser reads the user document, a missing document is an empty user.
+func loadUser(ctx context.Context, userID string) (firestoreUser, error) {
+	var user firestoreUser
+	client, err := store.Client(ctx)
+	if err != nil {
+		return user, err
+	}
+	doc, err := client.Collection(firestoreUserCollection).Doc(userID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return user, nil
+	}
+	if err != nil {
+		return user, err
+	}
+	err = doc.DataTo(&user)
+	return user, err
+}
+
+// updateUser applies fn to the user's chats in a transaction, as the client writes to the same document.
+func updateUser(ctx context.Context, userID string, fn func(user *firestoreUser) error) error {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return err
+	}
+	ref := client.Collection(firestoreUserCollection).Doc(userID)
+	return client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var user firestoreUser
+		var stored []any
+		doc, err := tx.Get(ref)
+		switch {
+		case status.Code(err) == codes.NotFound:
+		case err != nil:
+			return err
+		default:
+			if err := doc.DataTo(&user); err != nil {
+				return err
+			}
+			stored, _ = doc.Data()[chatsField].([]any)
+		}
+		before := snapshot(user.Chats)
+		if err := fn(&user); err != nil {
+			return err
+		}
+		return tx.Set(ref, map[string]any{chatsField: mergeChats(stored, before, user.Chats)}, firestore.Merge([]string{chatsField}))
+	})
+}
+
+// mergeChats writes the chats back over the stored ones, matched by chat ID. Chats and messages fn left
+// as they were are written as stored, with the fields the client writes and firestoreChat doesn't model.
+// Changed chats keep those fields and get the changed ones, messages rewritten by the bot lose them,
+// as they described the message replaced.
+func mergeChats(stored []any, before map[int]*firestoreChat, chats []*firestoreChat) []any {
+	storedChats := make(map[int]map[string]any, len(stored))
+	for _, v := range stored {
+		m, _ := v.(map[string]any)
+		// clients writing numbers as doubles store the ID as float64
+		switch id := m["chat_id"].(type) {
+		case int64:
+			storedChats[int(id)] = m
+		case float64:
+			storedChats[int(id)] = m
+		}
+	}
+	merged := make([]any, 0, len(chats))
+	for _, c := range chats {
+		storedChat, ok := storedChats[c.ChatID]
+		prev := before[c.ChatID]
+		if ok && reflect.DeepEqual(prev, c) {
+			merged = append(merged, storedChat)
+			continue
+		}
+		if prev == nil {
+			prev = &firestoreChat{}
+		}
+		m := clone(storedChat)
+		m["chat_id"] = c.ChatID
+		if !ok || c.Title != prev.Title {
+			m["title"] = c.Title
+		}
+		if !ok || !c.CreatedAt.Equal(prev.CreatedAt) {
+			m["created_at"] = c.CreatedAt
+		}
+		if !ok || !c.UpdatedAt.Equal(prev.UpdatedAt) {
+			m["updated_at"] = c.UpdatedAt
+		}
+		storedMessages, _ := m["messages"].([]any)
+		messages := make([]any, 0, len(c.Messages))
+		for i, msg := range c.Messages {
+			if i < len(storedMessages) && i < len(prev.Messages) && reflect.DeepEqual(prev.Messages[i], msg) {
+				messages = append(messages, storedMessages[i])
+				continue
+			}
+			mm := map[string]any{"from": msg.From, "message": msg.Message}
+			if len(msg.Alternates) > 0 {
+				mm["alternates"] = msg.Alternates
+			}
+			messages = append(messages, mm)
+		}
+		m["messages"] = messages
+		merged = append(merged, m)
+	}
+	return merged
+}
+
+// snapshot copies the chats deep enough to tell afterwards which chats and messages fn changed.
+func snapshot(chats []*firestoreChat) map[int]*firestoreChat {
+	copies := make(map[int]*firestoreChat, len(chats))
+	for _, c := range chats {
+		cp := *c
+		cp.Messages = make([]*firestoreMessage, len(c.Messages))
+		for i, m := range c.Messages {
+			mc := *m
+			mc.Alternates = slices.Clone(m.Alternates)
+			cp.Messages[i] = &mc
+		}
+		copies[c.ChatID] = &cp
+	}
+	return copies
+}
+
+func clone(m map[string]any) map[string]any {
+	c := make(map[string]any, len(m))
+	maps.Copy(c, m)
+	return c
+}
+
+func findChat(chats []*firestoreChat, chatID int) *firestoreChat {
+	for _, c := range chats {
+		if c.ChatID == chatID {
+			return c
+		}
+	}
+	return nil
+}
+
+func answerAt(messages []*firestoreMessage, index int) (question, answer string, err error) {
+	if index < 0 || index >= len(messages) || messages[index].From != fromAI {
+		return "", "", ErrMessageNotFound
+	}
+	for i := index - 1; i >= 0; i-- {
+		if messages[i].From == fromUser {
+			question = messages[i].Message
+			break
+		}
+	}
+	return question, messages[index].Message, nil
+}
+
+func nextChatID(chats []*firestoreChat) int {
+	maxID := 0
+	for _, c := range chats {
+		maxID = max(maxID, c.ChatID)
+	}
+	return maxID + 1
+}
+
+func pageMessages(messages []*firestoreMessage, offset, limit int) []*firestoreMessage {
+	if offset >= len(messages) {
+		return nil
+	}
+	return messages[offset:min(offset+limit, len(messages))]
+}
+
+func toContractChat(c *firestoreChat) contract.Chat {
+	chat := contract.Chat{
+		ChatID:       c.ChatID,
+		Title:        c.Title,
+		CreatedAt:    c.CreatedAt,
+		UpdatedAt:    c.UpdatedAt,
+		MessageCount: len(c.Messages),
+	}
+	if len(c.Messages) > 0 {
+		last := toContractMessage(c.Messages[len(c.Messages)-1])
+		chat.LastMessage = &last
+	}
+	return chat
+}
+
+func toContractMessage(m *firestoreMessage) contract.ChatMessage {
+	message := contract.ChatMessage{From: m.From, Message: m.Message}
+	for _, a := range m.Alternates {
+		message.Alternates = append(message.Alternates, a.Message)
+	}
+	return message
+}
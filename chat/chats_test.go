@@ -0,0 +1,176 @@
+package chat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klipach/matchguru/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextChatID(t *testing.T) {
+	assert.Equal(t, 1, nextChatID(nil))
+	assert.Equal(t, 11, nextChatID([]*firestoreChat{{ChatID: 10}, {ChatID: 3}}))
+}
+
+func TestPageMessages(t *testing.T) {
+	messages := []*firestoreMessage{{Message: "1"}, {Message: "2"}, {Message: "3"}}
+
+	tests := []struct {
+		name     string
+		offset   int
+		limit    int
+		expected []*firestoreMessage
+	}{
+		{name: "first page", offset: 0, limit: 2, expected: messages[:2]},
+		{name: "last page", offset: 2, limit: 2, expected: messages[2:]},
+		{name: "whole chat", offset: 0, limit: 10, expected: messages},
+		{name: "offset past the end", offset: 3, limit: 2, expected: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, pageMessages(messages, tt.offset, tt.limit))
+		})
+	}
+}
+
+func TestToContractChat(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	c := toContractChat(&firestoreChat{
+		ChatID:    2,
+		Title:     "Arsenal vs Chelsea",
+		CreatedAt: now,
+		UpdatedAt: now,
+		Messages: []*firestoreMessage{
+			{From: fromUser, Message: "who wins?"},
+			{From: fromAI, Message: "Arsenal"},
+		},
+	})
+	assert.Equal(t, contract.Chat{
+		ChatID:       2,
+		Title:        "Arsenal vs Chelsea",
+		CreatedAt:    now,
+		UpdatedAt:    now,
+		MessageCount: 2,
+		LastMessage:  &contract.ChatMessage{From: fromAI, Message: "Arsenal"},
+	}, c)
+
+	assert.Nil(t, toContractChat(&firestoreChat{ChatID: 1}).LastMessage)
+}
+
+func TestAnswerAt(t *testing.T) {
+	messages := []*firestoreMessage{
+		{From: fromUser, Message: "who wins?"},
+		{From: fromAI, Message: "Arsenal"},
+		{From: fromAI, Message: "Definitely Arsenal"},
+	}
+
+	question, answer, err := answerAt(messages, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "who wins?", question)
+	assert.Equal(t, "Arsenal", answer)
+
+	question, answer, err = answerAt(messages, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, "who wins?", question)
+	assert.Equal(t, "Definitely Arsenal", answer)
+
+	for _, index := range []int{-1, 0, 3} {
+		_, _, err := answerAt(messages, index)
+		assert.Equal(t, ErrMessageNotFound, err, "index %d", index)
+	}
+}
+
+func TestMergeChats(t *testing.T) {
+	created := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	updated := created.Add(time.Hour)
+	untitled := map[string]any{
+		"chat_id":  int64(3),
+		"messages": []any{map[string]any{"from": fromUser, "message": "hi", "sent_at": created}},
+	}
+	stored := []any{
+		map[string]any{
+			"chat_id":    int64(0),
+			"title":      "old title",
+			"created_at": created,
+			"updated_at": created,
+			"pinned":     true,
+			"messages": []any{
+				map[string]any{"from": fromUser, "message": "who will win?", "sent_at": created},
+				map[string]any{"from": fromAI, "message": "Arsenal", "liked": true},
+			},
+		},
+		map[string]any{"chat_id": int64(1), "title": "deleted"},
+		untitled,
+	}
+	before := map[int]*firestoreChat{
+		0: {
+			ChatID:    0,
+			Title:     "old title",
+			CreatedAt: created,
+			UpdatedAt: created,
+			Messages: []*firestoreMessage{
+				{From: fromUser, Message: "who will win?"},
+				{From: fromAI, Message: "Arsenal"},
+			},
+		},
+		1: {ChatID: 1, Title: "deleted"},
+		3: {ChatID: 3, Messages: []*firestoreMessage{{From: fromUser, Message: "hi"}}},
+	}
+	chats := []*firestoreChat{
+		{
+			ChatID:    0,
+			Title:     "Arsenal vs Chelsea",
+			CreatedAt: created,
+			UpdatedAt: updated,
+			Messages: []*firestoreMessage{
+				{From: fromUser, Message: "who will win?"},
+				{From: fromAI, Message: "Chelsea", Alternates: []*firestoreAlternate{{Message: "Arsenal", ReplacedAt: updated}}},
+			},
+		},
+		{ChatID: 2, Title: "new", CreatedAt: updated, UpdatedAt: updated},
+		{ChatID: 3, Messages: []*firestoreMessage{{From: fromUser, Message: "hi"}}},
+	}
+
+	merged := mergeChats(stored, before, chats)
+	assert.Equal(t, []any{
+		map[string]any{
+			"chat_id":    0,
+			"title":      "Arsenal vs Chelsea",
+			"created_at": created,
+			"updated_at": updated,
+			"pinned":     true,
+			"messages": []any{
+				map[string]any{"from": fromUser, "message": "who will win?", "sent_at": created},
+				// the rewritten answer doesn't keep the fields of the one it replaced
+				map[string]any{"from": fromAI, "message": "Chelsea", "alternates": []*firestoreAlternate{{Message: "Arsenal", ReplacedAt: updated}}},
+			},
+		},
+		map[string]any{
+			"chat_id":    2,
+			"title":      "new",
+			"created_at": updated,
+			"updated_at": updated,
+			"messages":   []any{},
+		},
+		untitled,
+	}, merged)
+	// the untouched chat gets no zero title or timestamps
+	assert.NotContains(t, merged[2], "title")
+	assert.NotContains(t, merged[2], "created_at")
+	// the stored chats are not modified
+	assert.Equal(t, "old title", stored[0].(map[string]any)["title"])
+}
+
+func TestSnapshot(t *testing.T) {
+	chats := []*firestoreChat{{ChatID: 1, Messages: []*firestoreMessage{{From: fromAI, Message: "Arsenal"}}}}
+	before := snapshot(chats)
+
+	chats[0].Messages[0].Message = "Chelsea"
+	chats[0].Messages[0].Alternates = []*firestoreAlternate{{Message: "Arsenal"}}
+
+	assert.Equal(t, "Arsenal", before[1].Messages[0].Message)
+	assert.Empty(t, before[1].Messages[0].Alternates)
+}
@@ -4,10 +4,10 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"time"
 
-	"cloud.google.com/go/compute/metadata"
-	"cloud.google.com/go/firestore"
 	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/store"
 	"github.com/tmc/langchaingo/llms"
 )
 
@@ -23,67 +23,80 @@ type firestoreUser struct {
 }
 
 type firestoreMessage struct {
-	From    string `firestore:"from"`
-	Message string `firestore:"message"`
+	From       string                `firestore:"from"`
+	Message    string                `firestore:"message"`
+	Alternates []*firestoreAlternate `firestore:"alternates,omitempty"`
+}
+
+// firestoreAlternate is a prior version of a message, replaced by regenerate or edit.
+type firestoreAlternate struct {
+	Message    string    `firestore:"message"`
+	ReplacedAt time.Time `firestore:"replaced_at"`
 }
 
 type firestoreChat struct {
-	ChatID   int                 `firestore:"chat_id"`
-	Messages []*firestoreMessage `firestore:"messages"`
+	ChatID    int                 `firestore:"chat_id"`
+	Title     string              `firestore:"title"`
+	CreatedAt time.Time           `firestore:"created_at"`
+	UpdatedAt time.Time           `firestore:"updated_at"`
+	Messages  []*firestoreMessage `firestore:"messages"`
 }
 
-func LoadHistory(ctx context.Context, userID string, chatID int) ([]llms.MessageContent, error) {
-	logger := log.LoggerFromContext(ctx)
+// History is a chat as it is sent to the model.
+type History struct {
+	Title    string
+	Messages []llms.MessageContent
+	// Length is the number of messages in the chat, Messages may keep only the last ones
+	Length int
+}
 
-	var chatHistory []llms.MessageContent
+// LoadHistory loads the chat messages as LLM messages, keeping only the last depth messages when depth > 0.
+func LoadHistory(ctx context.Context, userID string, chatID int, depth int) (History, error) {
+	logger := log.LoggerFromContext(ctx)
 
-	projectID, err := metadata.ProjectIDWithContext(ctx)
-	if err != nil {
-		return chatHistory, err
-	}
+	var history History
 
-	firestoreClient, err := firestore.NewClient(ctx, projectID)
+	firestoreClient, err := store.Client(ctx)
 	if err != nil {
-		return chatHistory, err
+		return history, err
 	}
-	defer firestoreClient.Close()
 
 	userDoc, err := firestoreClient.Collection(firestoreUserCollection).Doc(userID).Get(ctx)
 	if err != nil {
-		return chatHistory, err
+		return history, err
 	}
 	if !userDoc.Exists() {
-		logger.Warn("user not found", slog.String("userID", userID))
-		return chatHistory, nil
+		logger.Warn("user not found", slog.String("userID", log.UserID(userID)))
+		return history, nil
 	}
 
 	user := firestoreUser{}
 	userDoc.DataTo(&user)
 
-	messages := findChatMessages(user.Chats, chatID)
-	if messages == nil {
+	c := findChat(user.Chats, chatID)
+	if c == nil {
 		logger.Warn("chat not found", slog.Int("chatID", chatID))
-		return chatHistory, nil
+		return history, nil
 	}
+	history.Title = c.Title
+	history.Length = len(c.Messages)
 
-	for _, m := range messages {
+	for _, m := range lastMessages(c.Messages, depth) {
 		switch m.From {
 		case fromUser:
-			chatHistory = append(chatHistory, llms.TextParts(llms.ChatMessageTypeHuman, m.Message))
+			history.Messages = append(history.Messages, llms.TextParts(llms.ChatMessageTypeHuman, m.Message))
 		case fromAI:
-			chatHistory = append(chatHistory, llms.TextParts(llms.ChatMessageTypeAI, m.Message))
+			history.Messages = append(history.Messages, llms.TextParts(llms.ChatMessageTypeAI, m.Message))
 		default:
-			return chatHistory, fmt.Errorf("invalid message role: %s", m.From)
+			return history, fmt.Errorf("invalid message role: %s", m.From)
 		}
 	}
-	return chatHistory, nil
+	return history, nil
 }
 
-func findChatMessages(chats []*firestoreChat, chatID int) []*firestoreMessage {
-	for _, chat := range chats {
-		if chat.ChatID == chatID {
-			return chat.Messages
-		}
+func lastMessages(messages []*firestoreMessage, depth int) []*firestoreMessage {
+	if depth <= 0 || len(messages) <= depth {
+		return messages
 	}
-	return nil
+	return messages[len(messages)-depth:]
 }
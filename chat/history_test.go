@@ -5,12 +5,12 @@ import (
 	"testing"
 )
 
-func TestFindChatMessages(t *testing.T) {
+func TestFindChat(t *testing.T) {
 	tests := []struct {
 		name     string
 		chats    []*firestoreChat
 		chatID   int
-		expected []*firestoreMessage
+		expected *firestoreChat
 	}{
 		{
 			name: "Chat found",
@@ -19,7 +19,7 @@ func TestFindChatMessages(t *testing.T) {
 				{ChatID: 2, Messages: []*firestoreMessage{{}}},
 			},
 			chatID:   1,
-			expected: []*firestoreMessage{{}, {}},
+			expected: &firestoreChat{ChatID: 1, Messages: []*firestoreMessage{{}, {}}},
 		},
 		{
 			name: "Chat not found",
@@ -46,9 +46,37 @@ func TestFindChatMessages(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			result := findChatMessages(test.chats, test.chatID)
+			result := findChat(test.chats, test.chatID)
+			if !reflect.DeepEqual(result, test.expected) {
+				t.Errorf("findChat(%v, %d) = %v; want %v", test.chats, test.chatID, result, test.expected)
+			}
+		})
+	}
+}
+
+func TestLastMessages(t *testing.T) {
+	messages := []*firestoreMessage{
+		{From: fromUser, Message: "1"},
+		{From: fromAI, Message: "2"},
+		{From: fromUser, Message: "3"},
+	}
+
+	tests := []struct {
+		name     string
+		depth    int
+		expected []*firestoreMessage
+	}{
+		{name: "unlimited depth", depth: 0, expected: messages},
+		{name: "depth bigger than history", depth: 5, expected: messages},
+		{name: "depth equal to history", depth: 3, expected: messages},
+		{name: "depth smaller than history", depth: 2, expected: messages[1:]},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result := lastMessages(messages, test.depth)
 			if !reflect.DeepEqual(result, test.expected) {
-				t.Errorf("findChatMessages(%v, %d) = %v; want %v", test.chats, test.chatID, result, test.expected)
+				t.Errorf("lastMessages(%v, %d) = %v; want %v", messages, test.depth, result, test.expected)
 			}
 		})
 	}
@@ -0,0 +1,97 @@
+package chat
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+var (
+	ErrNoAnswer   = errors.New("chat doesn't end with an answer")
+	ErrNoQuestion = errors.New("chat has no question to edit")
+)
+
+// WithoutLastAnswer returns the messages the last answer was generated from, to generate it again.
+func (h History) WithoutLastAnswer() ([]llms.MessageContent, error) {
+	n := len(h.Messages)
+	if n < 2 || h.Messages[n-1].Role != llms.ChatMessageTypeAI || h.Messages[n-2].Role != llms.ChatMessageTypeHuman {
+		return nil, ErrNoAnswer
+	}
+	return h.Messages[:n-1], nil
+}
+
+// WithoutLastQuestion returns the messages preceding the last user message, dropping its answer if any.
+func (h History) WithoutLastQuestion() ([]llms.MessageContent, error) {
+	messages := h.Messages
+	if n := len(messages); n > 0 && messages[n-1].Role == llms.ChatMessageTypeAI {
+		messages = messages[:n-1]
+	}
+	n := len(messages)
+	if n == 0 || messages[n-1].Role != llms.ChatMessageTypeHuman {
+		return nil, ErrNoQuestion
+	}
+	return messages[:n-1], nil
+}
+
+// ReplaceLastAnswer stores a regenerated answer, keeping the previous one as an alternate.
+func ReplaceLastAnswer(ctx context.Context, userID string, chatID int, answer string) error {
+	return updateUser(ctx, userID, func(user *firestoreUser) error {
+		c := findChat(user.Chats, chatID)
+		if c == nil {
+			return ErrChatNotFound
+		}
+		return replaceLastAnswer(c, answer, time.Now().UTC())
+	})
+}
+
+// EditLastQuestion stores an edited user message and the answer to it, the previous
+// question and answer are kept as alternates.
+func EditLastQuestion(ctx context.Context, userID string, chatID int, question, answer string) error {
+	return updateUser(ctx, userID, func(user *firestoreUser) error {
+		c := findChat(user.Chats, chatID)
+		if c == nil {
+			return ErrChatNotFound
+		}
+		return editLastQuestion(c, question, answer, time.Now().UTC())
+	})
+}
+
+func replaceLastAnswer(c *firestoreChat, answer string, now time.Time) error {
+	n := len(c.Messages)
+	if n < 2 || c.Messages[n-1].From != fromAI || c.Messages[n-2].From != fromUser {
+		return ErrNoAnswer
+	}
+	replace(c.Messages[n-1], answer, now)
+	c.UpdatedAt = now
+	return nil
+}
+
+func editLastQuestion(c *firestoreChat, question, answer string, now time.Time) error {
+	var lastAnswer *firestoreMessage
+	messages := c.Messages
+	if n := len(messages); n > 0 && messages[n-1].From == fromAI {
+		lastAnswer = messages[n-1]
+		messages = messages[:n-1]
+	}
+	n := len(messages)
+	if n == 0 || messages[n-1].From != fromUser {
+		return ErrNoQuestion
+	}
+	replace(messages[n-1], question, now)
+
+	if lastAnswer != nil {
+		replace(lastAnswer, answer, now)
+	} else {
+		lastAnswer = &firestoreMessage{From: fromAI, Message: answer}
+	}
+	c.Messages = append(messages, lastAnswer)
+	c.UpdatedAt = now
+	return nil
+}
+
+func replace(m *firestoreMessage, message string, now time.Time) {
+	m.Alternates = append(m.Alternates, &firestoreAlternate{Message: m.Message, ReplacedAt: now})
+	m.Message = message
+}
@@ -0,0 +1,101 @@
+package chat
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+var (
+	question = llms.TextParts(llms.ChatMessageTypeHuman, "who wins?")
+	answer   = llms.TextParts(llms.ChatMessageTypeAI, "Arsenal")
+)
+
+func TestWithoutLastAnswer(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []llms.MessageContent
+		expected []llms.MessageContent
+		err      error
+	}{
+		{name: "answered question", messages: []llms.MessageContent{question, answer, question, answer}, expected: []llms.MessageContent{question, answer, question}},
+		{name: "unanswered question", messages: []llms.MessageContent{question, answer, question}, err: ErrNoAnswer},
+		{name: "answer without question", messages: []llms.MessageContent{answer}, err: ErrNoAnswer},
+		{name: "empty chat", messages: nil, err: ErrNoAnswer},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := History{Messages: tt.messages}.WithoutLastAnswer()
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expected, messages)
+		})
+	}
+}
+
+func TestWithoutLastQuestion(t *testing.T) {
+	tests := []struct {
+		name     string
+		messages []llms.MessageContent
+		expected []llms.MessageContent
+		err      error
+	}{
+		{name: "answered question", messages: []llms.MessageContent{question, answer, question, answer}, expected: []llms.MessageContent{question, answer}},
+		{name: "unanswered question", messages: []llms.MessageContent{question, answer, question}, expected: []llms.MessageContent{question, answer}},
+		{name: "single question", messages: []llms.MessageContent{question}, expected: []llms.MessageContent{}},
+		{name: "answer without question", messages: []llms.MessageContent{answer}, err: ErrNoQuestion},
+		{name: "empty chat", messages: nil, err: ErrNoQuestion},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			messages, err := History{Messages: tt.messages}.WithoutLastQuestion()
+			assert.Equal(t, tt.err, err)
+			assert.Equal(t, tt.expected, messages)
+		})
+	}
+}
+
+func TestReplaceLastAnswer(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	c := &firestoreChat{Messages: []*firestoreMessage{
+		{From: fromUser, Message: "who wins?"},
+		{From: fromAI, Message: "Arsenal"},
+	}}
+
+	require.NoError(t, replaceLastAnswer(c, "Chelsea", now))
+	assert.Equal(t, []*firestoreMessage{
+		{From: fromUser, Message: "who wins?"},
+		{From: fromAI, Message: "Chelsea", Alternates: []*firestoreAlternate{{Message: "Arsenal", ReplacedAt: now}}},
+	}, c.Messages)
+	assert.Equal(t, now, c.UpdatedAt)
+
+	assert.Equal(t, ErrNoAnswer, replaceLastAnswer(&firestoreChat{Messages: []*firestoreMessage{{From: fromUser}}}, "Chelsea", now))
+}
+
+func TestEditLastQuestion(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	c := &firestoreChat{Messages: []*firestoreMessage{
+		{From: fromUser, Message: "who wns?"},
+		{From: fromAI, Message: "Sorry?"},
+	}}
+	require.NoError(t, editLastQuestion(c, "who wins?", "Arsenal", now))
+	assert.Equal(t, []*firestoreMessage{
+		{From: fromUser, Message: "who wins?", Alternates: []*firestoreAlternate{{Message: "who wns?", ReplacedAt: now}}},
+		{From: fromAI, Message: "Arsenal", Alternates: []*firestoreAlternate{{Message: "Sorry?", ReplacedAt: now}}},
+	}, c.Messages)
+
+	// the answer to the previous version may have never been stored
+	c = &firestoreChat{Messages: []*firestoreMessage{{From: fromUser, Message: "who wns?"}}}
+	require.NoError(t, editLastQuestion(c, "who wins?", "Arsenal", now))
+	assert.Equal(t, []*firestoreMessage{
+		{From: fromUser, Message: "who wins?", Alternates: []*firestoreAlternate{{Message: "who wns?", ReplacedAt: now}}},
+		{From: fromAI, Message: "Arsenal"},
+	}, c.Messages)
+
+	assert.Equal(t, ErrNoQuestion, editLastQuestion(&firestoreChat{}, "who wins?", "Arsenal", now))
+}
@@ -0,0 +1,89 @@
+package chat
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/tmc/langchaingo/llms"
+)
+
+const (
+	maxTitleLength      = 60
+	maxTitleTokens      = 20
+	maxTitleInputLength = 1000
+
+	// takes the sport name and the English name of the answer language
+	titlePrompt = `You name chats between a user and a %s analyst bot.
+Write a short title, at most 6 words, describing what the user asks about.
+Write the title in %s.
+Reply with the title only: no quotes, no markdown, no curly braces, no trailing punctuation.`
+)
+
+// matches {display name|english name} internal links
+var internalLinkRegex = regexp.MustCompile(`\{([^|}]*)\|[^}]*\}`)
+
+// GenerateTitle asks the model for a short chat title based on the first exchange, about the sport
+// and in the language the bot answers in, such as "cricket" and "Spanish".
+// The model response is returned as well, for usage accounting.
+func GenerateTitle(ctx context.Context, model llms.Model, sportName, language, userMessage, answer string) (string, *llms.ContentResponse, error) {
+	resp, err := model.GenerateContent(
+		ctx,
+		[]llms.MessageContent{
+			llms.TextParts(llms.ChatMessageTypeSystem, fmt.Sprintf(titlePrompt, sportName, language)),
+			llms.TextParts(llms.ChatMessageTypeHuman,
+				"User: "+truncate(userMessage, maxTitleInputLength)+"\nBot: "+truncate(answer, maxTitleInputLength),
+			),
+		},
+		llms.WithMaxTokens(maxTitleTokens),
+	)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return "", resp, nil
+	}
+	return cleanTitle(resp.Choices[0].Content), resp, nil
+}
+
+// SetTitle sets the chat title unless the chat has one already, e.g. set by the user meanwhile.
+// It reports whether the title was set.
+func SetTitle(ctx context.Context, userID string, chatID int, title string) (bool, error) {
+	set := false
+	err := updateUser(ctx, userID, func(user *firestoreUser) error {
+		c := findChat(user.Chats, chatID)
+		if c == nil {
+			return ErrChatNotFound
+		}
+		if c.Title != "" {
+			return nil
+		}
+		c.Title = title
+		c.UpdatedAt = time.Now().UTC()
+		set = true
+		return nil
+	})
+	return set, err
+}
+
+// cleanTitle removes the decorations models tend to add despite the instructions.
+func cleanTitle(title string) string {
+	title = strings.TrimSpace(title)
+	if i := strings.IndexByte(title, '\n'); i >= 0 {
+		title = title[:i]
+	}
+	title = internalLinkRegex.ReplaceAllString(title, "$1")
+	title = strings.NewReplacer("{", "", "}", "", "*", "", "#", "").Replace(title)
+	title = strings.Trim(title, ` "'«»“”.`)
+	return strings.TrimSpace(truncate(title, maxTitleLength))
+}
+
+func truncate(s string, maxRunes int) string {
+	if utf8.RuneCountInString(s) <= maxRunes {
+		return s
+	}
+	return string([]rune(s)[:maxRunes])
+}
@@ -0,0 +1,59 @@
+package chat
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tmc/langchaingo/llms"
+)
+
+// titleModel answers with a fixed title and keeps the messages it was sent.
+type titleModel struct {
+	messages []llms.MessageContent
+}
+
+func (m *titleModel) GenerateContent(_ context.Context, messages []llms.MessageContent, _ ...llms.CallOption) (*llms.ContentResponse, error) {
+	m.messages = messages
+	return &llms.ContentResponse{Choices: []*llms.ContentChoice{{Content: `"Kohli's form"`}}}, nil
+}
+
+func (m *titleModel) Call(context.Context, string, ...llms.CallOption) (string, error) {
+	return "", nil
+}
+
+func TestCleanTitle(t *testing.T) {
+	tests := []struct {
+		name     string
+		title    string
+		expected string
+	}{
+		{name: "clean title", title: "Arsenal vs Chelsea prediction", expected: "Arsenal vs Chelsea prediction"},
+		{name: "quoted title", title: ` "Arsenal vs Chelsea prediction." `, expected: "Arsenal vs Chelsea prediction"},
+		{name: "markdown", title: "## **Прогноз на матч**", expected: "Прогноз на матч"},
+		{name: "internal link", title: "{Arsenal FC|Arsenal FC} form", expected: "Arsenal FC form"},
+		{name: "multiple lines", title: "Premier League table\nHere is the title", expected: "Premier League table"},
+		{name: "too long", title: strings.Repeat("ab", maxTitleLength), expected: strings.Repeat("ab", maxTitleLength/2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, cleanTitle(tt.title))
+		})
+	}
+}
+
+func TestGenerateTitle(t *testing.T) {
+	model := &titleModel{}
+	title, resp, err := GenerateTitle(context.Background(), model, "cricket", "Spanish", "¿Cómo está Kohli?", "Muy bien")
+	require.NoError(t, err)
+	assert.Equal(t, "Kohli's form", title)
+	assert.NotNil(t, resp)
+
+	require.Len(t, model.messages, 2)
+	system := model.messages[0].Parts[0].(llms.TextContent).Text
+	assert.Contains(t, system, "a cricket analyst bot")
+	assert.Contains(t, system, "Write the title in Spanish.")
+}
@@ -0,0 +1,144 @@
+package matchguru
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	fbauth "firebase.google.com/go/v4/auth"
+	"github.com/klipach/matchguru/chat"
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/log"
+)
+
+const (
+	maxChatTitleLength     = 100
+	defaultMessagesPerPage = 50
+	maxMessagesPerPage     = 200
+)
+
+func listChats(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	chats, err := chat.ListChats(r.Context(), token.UID)
+	if err != nil {
+		chatError(w, r, "error while listing chats", err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, contract.ChatsResponse{Chats: chats})
+}
+
+func createChat(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	var req contract.ChatRequest
+	// the body is optional, a chat without title gets one after the first answer
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+	title, ok := normalizeChatTitle(req.Title)
+	if !ok {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	c, err := chat.CreateChat(r.Context(), token.UID, title)
+	if err != nil {
+		chatError(w, r, "error while creating chat", err)
+		return
+	}
+	writeJSON(w, r, http.StatusCreated, c)
+}
+
+func renameChat(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	chatID, err := strconv.Atoi(r.PathValue("chatID"))
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	var req contract.ChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	title, ok := normalizeChatTitle(req.Title)
+	if !ok || title == "" {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	c, err := chat.RenameChat(r.Context(), token.UID, chatID, title)
+	if err != nil {
+		chatError(w, r, "error while renaming chat", err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, c)
+}
+
+func deleteChat(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	chatID, err := strconv.Atoi(r.PathValue("chatID"))
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	if err := chat.DeleteChat(r.Context(), token.UID, chatID); err != nil {
+		chatError(w, r, "error while deleting chat", err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// listMessages returns a page of chat messages selected by offset and limit query params.
+func listMessages(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	chatID, err := strconv.Atoi(r.PathValue("chatID"))
+	if err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	offset, err := queryInt(r, "offset", 0)
+	if err != nil || offset < 0 {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	limit, err := queryInt(r, "limit", defaultMessagesPerPage)
+	if err != nil || limit <= 0 || limit > maxMessagesPerPage {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	messages, total, err := chat.Messages(r.Context(), token.UID, chatID, offset, limit)
+	if err != nil {
+		chatError(w, r, "error while loading messages", err)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, contract.MessagesResponse{
+		Messages: messages,
+		Offset:   offset,
+		Total:    total,
+	})
+}
+
+func chatError(w http.ResponseWriter, r *http.Request, msg string, err error) {
+	if errors.Is(err, chat.ErrChatNotFound) || errors.Is(err, chat.ErrMessageNotFound) {
+		http.Error(w, "Not Found", http.StatusNotFound)
+		return
+	}
+	log.LoggerFromContext(r.Context()).Error(msg, slog.String(ErrorMsgLogField, err.Error()))
+	http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+}
+
+func normalizeChatTitle(title string) (string, bool) {
+	title = strings.TrimSpace(title)
+	return title, utf8.RuneCountInString(title) <= maxChatTitleLength
+}
+
+func queryInt(r *http.Request, name string, def int) (int, error) {
+	v := r.URL.Query().Get(name)
+	if v == "" {
+		return def, nil
+	}
+	return strconv.Atoi(v)
+}
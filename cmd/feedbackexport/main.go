@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	firebase "firebase.google.com/go/v4"
+	"github.com/klipach/matchguru/feedback"
+	"github.com/klipach/matchguru/fixture"
+	"github.com/klipach/matchguru/sport"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+const dateLayout = "2006-01-02"
+
+// SPORTMONKS_API_KEY=*** go run cmd/feedbackexport/main.go -from 2025-05-01 -to 2025-05-31 -format csv > feedback.csv
+// the created_at range query over the feedback collection group needs a collection group index on created_at,
+// the games are described from the fixture APIs when SPORTMONKS_API_KEY is set
+func main() {
+	ctx := context.Background()
+	fromPtr := flag.String("from", time.Now().AddDate(0, 0, -30).Format(dateLayout), "First day to export, inclusive")
+	toPtr := flag.String("to", time.Now().Format(dateLayout), "Last day to export, inclusive")
+	formatPtr := flag.String("format", "jsonl", "Output format: jsonl or csv")
+	ratingPtr := flag.String("rating", "", "Export only the given rating: up or down")
+	sportmonksURLPtr := flag.String("sportmonks-url", "https://api.sportmonks.com", "SportMonks football API base URL")
+	cricketURLPtr := flag.String("cricket-url", "https://cricket.sportmonks.com", "SportMonks cricket API base URL")
+	flag.Parse()
+
+	from, err := time.Parse(dateLayout, *fromPtr)
+	if err != nil {
+		log.Fatalf("invalid -from: %v", err)
+	}
+	to, err := time.Parse(dateLayout, *toPtr)
+	if err != nil {
+		log.Fatalf("invalid -to: %v", err)
+	}
+
+	absPath, err := filepath.Abs("./service_account_key.json")
+	if err != nil {
+		log.Fatalf("failed to get absolute path: %v", err)
+	}
+	app, err := firebase.NewApp(ctx, nil, option.WithCredentialsFile(absPath))
+	if err != nil {
+		log.Fatalf("error initializing app: %v", err)
+	}
+	client, err := app.Firestore(ctx)
+	if err != nil {
+		log.Fatalf("error getting Firestore client: %v", err)
+	}
+	defer client.Close()
+
+	var fixtures *fixtureResolver
+	if apiKey := os.Getenv("SPORTMONKS_API_KEY"); apiKey != "" {
+		fixtures = newFixtureResolver(map[sport.Sport]fixture.Fetcher{
+			sport.Football: fixture.NewClient(apiKey, *sportmonksURLPtr),
+			sport.Cricket:  fixture.NewCricketClient(apiKey, *cricketURLPtr),
+		})
+	} else {
+		log.Printf("SPORTMONKS_API_KEY is not set, games are exported by ID only")
+	}
+
+	var write func(f feedback.Feedback) error
+	switch *formatPtr {
+	case "jsonl":
+		enc := json.NewEncoder(os.Stdout)
+		write = func(f feedback.Feedback) error { return enc.Encode(f) }
+	case "csv":
+		w := csv.NewWriter(os.Stdout)
+		defer w.Flush()
+		if err := w.Write(csvHeader); err != nil {
+			log.Fatalf("error writing csv: %v", err)
+		}
+		write = func(f feedback.Feedback) error { return w.Write(csvRecord(f)) }
+	default:
+		log.Fatalf("unknown format %q", *formatPtr)
+	}
+
+	query := client.CollectionGroup(feedback.Collection).
+		Where("created_at", ">=", from).
+		Where("created_at", "<", to.AddDate(0, 0, 1))
+	if *ratingPtr != "" {
+		query = query.Where("rating", "==", *ratingPtr)
+	}
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+
+	count := 0
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error reading feedback: %v", err)
+		}
+		var f feedback.Feedback
+		if err := doc.DataTo(&f); err != nil {
+			log.Fatalf("error decoding %s: %v", doc.Ref.Path, err)
+		}
+		if f.Fixture == "" && fixtures != nil {
+			f.Fixture = fixtures.describe(ctx, f.Sport, f.GameID)
+		}
+		if err := write(f); err != nil {
+			log.Fatalf("error writing feedback: %v", err)
+		}
+		count++
+	}
+	log.Printf("exported %d feedback entries", count)
+}
+
+var csvHeader = []string{
+	"created_at", "user_id", "chat_id", "message_index", "rating", "category", "comment",
+	"question", "answer", "model", "prompt_version", "sport", "game_id", "fixture",
+}
+
+func csvRecord(f feedback.Feedback) []string {
+	return []string{
+		f.CreatedAt.Format(time.RFC3339),
+		f.UserID,
+		strconv.Itoa(f.ChatID),
+		strconv.Itoa(f.MessageIndex),
+		f.Rating,
+		f.Category,
+		f.Comment,
+		f.Question,
+		f.Answer,
+		f.Model,
+		f.PromptVersion,
+		f.Sport,
+		strconv.Itoa(f.GameID),
+		f.Fixture,
+	}
+}
+
+// fixtureResolver describes the rated games, a game rated many times is fetched once.
+type fixtureResolver struct {
+	fetchers     map[sport.Sport]fixture.Fetcher
+	descriptions map[string]string
+}
+
+func newFixtureResolver(fetchers map[sport.Sport]fixture.Fetcher) *fixtureResolver {
+	return &fixtureResolver{fetchers: fetchers, descriptions: map[string]string{}}
+}
+
+// describe returns the name, the league and the start of the game, empty when it can't be fetched.
+func (r *fixtureResolver) describe(ctx context.Context, sportName string, gameID int) string {
+	if gameID == 0 {
+		return ""
+	}
+	sp, err := sport.Parse(sportName)
+	if err != nil {
+		log.Printf("game %d: %v", gameID, err)
+		return ""
+	}
+	key := string(sp) + "/" + strconv.Itoa(gameID)
+	if d, ok := r.descriptions[key]; ok {
+		return d
+	}
+	var description string
+	if fetcher, ok := r.fetchers[sp]; ok {
+		f, err := fetcher.Fetch(ctx, gameID)
+		if err != nil {
+			log.Printf("error fetching %s game %d: %v", sp, gameID, err)
+		} else {
+			description = f.Name + ", " + f.League.Name + ", " + f.StartingAt.UTC().Format(time.RFC3339)
+		}
+	}
+	r.descriptions[key] = description
+	return description
+}
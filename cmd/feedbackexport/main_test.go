@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/klipach/matchguru/fixture"
+	"github.com/klipach/matchguru/sport"
+	"github.com/stretchr/testify/assert"
+)
+
+type countingFetcher struct {
+	calls int
+}
+
+func (f *countingFetcher) Fetch(_ context.Context, fixtureID int) (*fixture.Fixture, error) {
+	f.calls++
+	if fixtureID == 404 {
+		return nil, errors.New("not found")
+	}
+	return &fixture.Fixture{
+		Name:       "Arsenal vs Chelsea",
+		League:     fixture.League{Name: "Premier League"},
+		StartingAt: time.Date(2025, 5, 1, 14, 0, 0, 0, time.FixedZone("BST", 3600)),
+	}, nil
+}
+
+func TestFixtureResolver(t *testing.T) {
+	ctx := context.Background()
+	football := &countingFetcher{}
+	r := newFixtureResolver(map[sport.Sport]fixture.Fetcher{sport.Football: football})
+
+	// an empty sport is football, as in the requests
+	assert.Equal(t, "Arsenal vs Chelsea, Premier League, 2025-05-01T13:00:00Z", r.describe(ctx, "", 1))
+	assert.Equal(t, "Arsenal vs Chelsea, Premier League, 2025-05-01T13:00:00Z", r.describe(ctx, "football", 1))
+	assert.Equal(t, 1, football.calls)
+
+	assert.Empty(t, r.describe(ctx, "football", 0))
+	assert.Empty(t, r.describe(ctx, "football", 404))
+	assert.Empty(t, r.describe(ctx, "football", 404))
+	assert.Empty(t, r.describe(ctx, "cricket", 1))
+	assert.Equal(t, 2, football.calls)
+}
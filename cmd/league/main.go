@@ -0,0 +1,220 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/klipach/matchguru/dictionary"
+	"github.com/klipach/matchguru/sport"
+)
+
+// source is where the leagues of a sport are fetched from.
+type source struct {
+	comment string
+	pageURL func(apiKey string, page int) string
+}
+
+var sources = map[sport.Sport]source{
+	sport.Football: {
+		comment: "generated by cmd/league from https://api.sportmonks.com/v3/football/leagues?include=country\nleague name should be in lowercase",
+		pageURL: func(_ string, page int) string {
+			return fmt.Sprintf("https://api.sportmonks.com/v3/football/leagues?include=country&per_page=50&page=%d", page)
+		},
+	},
+	// the cricket API is a separate v2 API authenticated with the api_token query parameter,
+	// its leagues have no country to qualify the names with
+	sport.Cricket: {
+		comment: "generated by cmd/league -sport cricket from https://cricket.sportmonks.com/api/v2.0/leagues\nleague name should be in lowercase",
+		pageURL: func(apiKey string, page int) string {
+			return "https://cricket.sportmonks.com/api/v2.0/leagues?" + url.Values{"api_token": {apiKey}, "page": {strconv.Itoa(page)}}.Encode()
+		},
+	},
+}
+
+type League struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Country struct {
+		Name string `json:"name"`
+	} `json:"country"`
+}
+
+// demonyms qualify league names the way people say them, "german bundesliga" rather than "germany bundesliga"
+var demonyms = map[string]string{
+	"argentina":   "argentine",
+	"austria":     "austrian",
+	"belgium":     "belgian",
+	"brazil":      "brazilian",
+	"croatia":     "croatian",
+	"czechia":     "czech",
+	"denmark":     "danish",
+	"england":     "english",
+	"france":      "french",
+	"germany":     "german",
+	"greece":      "greek",
+	"italy":       "italian",
+	"japan":       "japanese",
+	"mexico":      "mexican",
+	"netherlands": "dutch",
+	"norway":      "norwegian",
+	"poland":      "polish",
+	"portugal":    "portuguese",
+	"russia":      "russian",
+	"scotland":    "scottish",
+	"serbia":      "serbian",
+	"spain":       "spanish",
+	"sweden":      "swedish",
+	"switzerland": "swiss",
+	"turkey":      "turkish",
+	"ukraine":     "ukrainian",
+	"usa":         "american",
+	"wales":       "welsh",
+}
+
+// regions are not countries, their competitions are only known by name
+var regions = map[string]bool{
+	"world":         true,
+	"europe":        true,
+	"africa":        true,
+	"asia":          true,
+	"north america": true,
+	"south america": true,
+	"oceania":       true,
+}
+
+// collision is an alias claimed by more than one league, the lowest ID keeps it.
+type collision struct {
+	alias string
+	ids   []int
+}
+
+// SPORTMONKS_API_KEY=*** go run cmd/league/main.go -w
+// without -w the generated filter/league.go is printed to stdout,
+// -sport cricket generates filter/cricket_league.go
+func main() {
+	writePtr := flag.Bool("w", false, "Write the result to the dictionary of the sport instead of stdout")
+	filterDirPtr := flag.String("filter-dir", "filter", "Directory of the filter package holding the dictionaries")
+	sportPtr := flag.String("sport", string(sport.Default), "Sport of the leagues, football or cricket")
+	flag.Parse()
+
+	sp, err := sport.Parse(*sportPtr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	src := sources[sp]
+
+	apiKey := os.Getenv("SPORTMONKS_API_KEY")
+	ctx := context.Background()
+	leagues, err := FetchLeagues(ctx, apiKey, src)
+	if err != nil {
+		log.Fatalf("Failed to fetch leagues: %v", err)
+	}
+
+	aliases, collisions := buildAliases(leagues)
+	for _, c := range collisions {
+		log.Printf("alias %q claimed by leagues %v, kept %d", c.alias, c.ids, c.ids[0])
+	}
+
+	// aliases added by hand or by cmd/linkmine, such as sponsor names, can't be derived
+	file := dictionary.Leagues(*filterDirPtr, sp)
+	existing, err := file.Read()
+	if err != nil {
+		log.Fatalf("Failed to read existing leagues: %v", err)
+	}
+	kept := mergeExisting(aliases, existing)
+	log.Printf("total aliases: %d, kept from %s: %d", len(aliases), file.Path, kept)
+
+	out, err := dictionary.Render(file.VarName, src.comment, aliases)
+	if err != nil {
+		log.Fatalf("Failed to render leagues: %v", err)
+	}
+	if !*writePtr {
+		os.Stdout.Write(out)
+		return
+	}
+	if err := os.WriteFile(file.Path, out, 0o644); err != nil {
+		log.Fatalf("Failed to write leagues: %v", err)
+	}
+}
+
+// FetchLeagues fetches all leagues of the source from the SportMonks API
+func FetchLeagues(ctx context.Context, apiKey string, src source) ([]League, error) {
+	var leagues []League
+	for page := 1; ; page++ {
+		leagueResponse, err := dictionary.FetchPage[League](ctx, apiKey, src.pageURL(apiKey, page))
+		if err != nil {
+			return nil, fmt.Errorf("page %d: %w", page, err)
+		}
+		log.Printf("page %d: Got %d leagues", page, len(leagueResponse.Data))
+		leagues = append(leagues, leagueResponse.Data...)
+
+		if !leagueResponse.Pagination.HasMore || len(leagueResponse.Data) == 0 {
+			break
+		}
+	}
+	log.Printf("total leagues fetched: %d", len(leagues))
+	return leagues, nil
+}
+
+// buildAliases maps the lowercase league names, and the names qualified by country, to league IDs.
+// "bundesliga" is claimed by the German and the Austrian leagues, the lowest ID keeps the bare name
+// and both stay reachable as "german bundesliga" and "austrian bundesliga".
+func buildAliases(leagues []League) (map[string]int, []collision) {
+	claims := map[string][]int{}
+	claim := func(alias string, id int) {
+		alias = strings.Join(strings.Fields(strings.ToLower(alias)), " ")
+		if alias != "" && !slices.Contains(claims[alias], id) {
+			claims[alias] = append(claims[alias], id)
+		}
+	}
+	for _, l := range leagues {
+		name := strings.ToLower(l.Name)
+		claim(name, l.ID)
+
+		country := strings.ToLower(l.Country.Name)
+		if country == "" || regions[country] {
+			continue
+		}
+		demonym := demonyms[country]
+		if strings.Contains(name, country) || (demonym != "" && strings.Contains(name, demonym)) {
+			continue
+		}
+		claim(country+" "+name, l.ID)
+		if demonym != "" {
+			claim(demonym+" "+name, l.ID)
+		}
+	}
+
+	aliases := map[string]int{}
+	var collisions []collision
+	for alias, ids := range claims {
+		slices.Sort(ids)
+		aliases[alias] = ids[0]
+		if len(ids) > 1 {
+			collisions = append(collisions, collision{alias: alias, ids: ids})
+		}
+	}
+	slices.SortFunc(collisions, func(a, b collision) int { return strings.Compare(a.alias, b.alias) })
+	return aliases, collisions
+}
+
+// mergeExisting adds the existing aliases which are not generated, placeholders mapped to 0 are dropped.
+// It returns how many aliases were kept.
+func mergeExisting(aliases, existing map[string]int) int {
+	kept := 0
+	for alias, id := range existing {
+		if _, ok := aliases[alias]; ok || id == 0 {
+			continue
+		}
+		aliases[alias] = id
+		kept++
+	}
+	return kept
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func league(id int, name, country string) League {
+	l := League{ID: id, Name: name}
+	l.Country.Name = country
+	return l
+}
+
+func TestBuildAliases(t *testing.T) {
+	tests := []struct {
+		name               string
+		leagues            []League
+		expected           map[string]int
+		expectedCollisions []collision
+	}{
+		{
+			name:    "country and demonym qualifiers",
+			leagues: []League{league(8, "Premier League", "England")},
+			expected: map[string]int{
+				"premier league":         8,
+				"england premier league": 8,
+				"english premier league": 8,
+			},
+		},
+		{
+			name:    "collision keeps the lowest id",
+			leagues: []League{league(181, "Bundesliga", "Austria"), league(82, "Bundesliga", "Germany")},
+			expected: map[string]int{
+				"bundesliga":          82,
+				"austria bundesliga":  181,
+				"austrian bundesliga": 181,
+				"germany bundesliga":  82,
+				"german bundesliga":   82,
+			},
+			expectedCollisions: []collision{{alias: "bundesliga", ids: []int{82, 181}}},
+		},
+		{
+			name:     "regions are not qualified",
+			leagues:  []League{league(2, "Champions League", "Europe")},
+			expected: map[string]int{"champions league": 2},
+		},
+		{
+			name:    "country already in the name",
+			leagues: []League{league(1659, "Super League", "Switzerland"), league(501, "Scottish  Premiership", "Scotland")},
+			expected: map[string]int{
+				"super league":             1659,
+				"switzerland super league": 1659,
+				"swiss super league":       1659,
+				"scottish premiership":     501,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			aliases, collisions := buildAliases(tt.leagues)
+			assert.Equal(t, tt.expected, aliases)
+			assert.Equal(t, tt.expectedCollisions, collisions)
+		})
+	}
+}
+
+func TestMergeExisting(t *testing.T) {
+	aliases := map[string]int{"premier league": 8}
+	existing := map[string]int{"premier league": 9, "epl": 8, "conference league": 0}
+
+	kept := mergeExisting(aliases, existing)
+	assert.Equal(t, 1, kept)
+	assert.Equal(t, map[string]int{"premier league": 8, "epl": 8}, aliases)
+}
@@ -0,0 +1,193 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/firestore"
+	firebase "firebase.google.com/go/v4"
+	"github.com/klipach/matchguru/dictionary"
+	"github.com/klipach/matchguru/linkmiss"
+	"github.com/klipach/matchguru/sport"
+	"google.golang.org/api/iterator"
+	"google.golang.org/api/option"
+)
+
+const sportmonksBaseURL = "https://api.sportmonks.com/v3/football"
+
+type searchAPIResponse struct {
+	Data []struct {
+		ID      int    `json:"id"`
+		Name    string `json:"name"`
+		Country struct {
+			Name string `json:"name"`
+		} `json:"country"`
+	} `json:"data"`
+}
+
+// candidate is a SportMonks team or league an unresolved name may refer to.
+type candidate struct {
+	entity  string // "league" or "team"
+	id      int
+	name    string
+	country string
+}
+
+// SPORTMONKS_API_KEY=*** go run cmd/linkmine/main.go -limit 20
+// lists the most frequent internal link names without mapping, searches SportMonks for each
+// and, once a candidate is picked, writes the mapping into filter/league.go or filter/team.go
+func main() {
+	ctx := context.Background()
+	limitPtr := flag.Int("limit", 20, "Number of most frequent unresolved names to review")
+	listPtr := flag.Bool("list", false, "Only list the unresolved names, without reviewing them")
+	filterDirPtr := flag.String("filter-dir", "filter", "Directory of the filter package holding the dictionaries")
+	flag.Parse()
+
+	apiKey := os.Getenv("SPORTMONKS_API_KEY")
+	if apiKey == "" && !*listPtr {
+		log.Fatalf("SPORTMONKS_API_KEY is required to search candidates")
+	}
+
+	absPath, err := filepath.Abs("./service_account_key.json")
+	if err != nil {
+		log.Fatalf("failed to get absolute path: %v", err)
+	}
+	app, err := firebase.NewApp(ctx, nil, option.WithCredentialsFile(absPath))
+	if err != nil {
+		log.Fatalf("error initializing app: %v", err)
+	}
+	client, err := app.Firestore(ctx)
+	if err != nil {
+		log.Fatalf("error getting Firestore client: %v", err)
+	}
+	defer client.Close()
+
+	// dismissed names keep their count, they are skipped here rather than filtered in the query,
+	// which would need a composite index and miss the documents written before the field existed
+	iter := client.Collection(linkmiss.Collection).OrderBy("count", firestore.Desc).Documents(ctx)
+	var misses []linkmiss.Miss
+	for len(misses) < *limitPtr {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			log.Fatalf("error listing unresolved links: %v", err)
+		}
+		var m linkmiss.Miss
+		if err := doc.DataTo(&m); err != nil {
+			log.Fatalf("error decoding %s: %v", doc.Ref.ID, err)
+		}
+		if !m.Dismissed {
+			misses = append(misses, m)
+		}
+	}
+	iter.Stop()
+
+	if *listPtr {
+		for _, m := range misses {
+			fmt.Printf("%6d  %s  (last seen %s)\n", m.Count, m.Name, m.LastSeen.Format("2006-01-02"))
+		}
+		return
+	}
+
+	dictionaries := map[string]dictionary.File{
+		"league": dictionary.Leagues(*filterDirPtr, sport.Football),
+		"team":   dictionary.Teams(*filterDirPtr, sport.Football),
+	}
+	in := bufio.NewScanner(os.Stdin)
+	for i, m := range misses {
+		fmt.Printf("\n[%d/%d] %q used %d times\n", i+1, len(misses), m.Name, m.Count)
+		candidates, err := search(ctx, apiKey, m.Name)
+		if err != nil {
+			log.Printf("error searching %q: %v", m.Name, err)
+			continue
+		}
+		for j, c := range candidates {
+			fmt.Printf("  %d) %-6s %7d  %s (%s)\n", j+1, c.entity, c.id, c.name, c.country)
+		}
+		fmt.Print("pick a candidate, s to skip, d to dismiss the name, q to quit: ")
+		if !in.Scan() {
+			return
+		}
+		answer := strings.TrimSpace(in.Text())
+		switch answer {
+		case "", "s":
+			continue
+		case "q":
+			return
+		case "d":
+			// not a team or league, such as a player or a tournament stage
+			dismissMiss(ctx, client, m.Name)
+			continue
+		}
+		n, err := strconv.Atoi(answer)
+		if err != nil || n < 1 || n > len(candidates) {
+			fmt.Println("invalid choice, skipped")
+			continue
+		}
+		c := candidates[n-1]
+		if err := dictionaries[c.entity].Add(m.Name, c.id); err != nil {
+			log.Fatalf("error writing mapping: %v", err)
+		}
+		fmt.Printf("mapped %q to %s %d\n", m.Name, c.entity, c.id)
+		// answers keep using the name until the new dictionary is deployed
+		dismissMiss(ctx, client, m.Name)
+	}
+}
+
+// search looks the name up among SportMonks leagues and teams.
+func search(ctx context.Context, apiKey, name string) ([]candidate, error) {
+	var candidates []candidate
+	for _, entity := range []string{"league", "team"} {
+		endpoint := fmt.Sprintf("%s/%ss/search/%s?include=country", sportmonksBaseURL, entity, url.PathEscape(name))
+		found, err := searchEntity(ctx, apiKey, endpoint)
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range found.Data {
+			candidates = append(candidates, candidate{entity: entity, id: f.ID, name: f.Name, country: f.Country.Name})
+		}
+	}
+	return candidates, nil
+}
+
+func searchEntity(ctx context.Context, apiKey, endpoint string) (*searchAPIResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", apiKey)
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	var found searchAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&found); err != nil {
+		return nil, err
+	}
+	return &found, nil
+}
+
+func dismissMiss(ctx context.Context, client *firestore.Client, name string) {
+	if err := linkmiss.Dismiss(ctx, client, name); err != nil {
+		log.Printf("error dismissing unresolved link %q: %v", name, err)
+	}
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"log/slog"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync/atomic"
+	"syscall"
+	"time"
+
+	"github.com/klipach/matchguru"
+	"github.com/klipach/matchguru/config"
+	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/telemetry"
+)
+
+// go run cmd/server/main.go -port 8080
+func main() {
+	port := os.Getenv("PORT") // set by Cloud Run
+	if port == "" {
+		port = "8080"
+	}
+	portPtr := flag.String("port", port, "Port to listen on")
+	shutdownTimeoutPtr := flag.Duration("shutdown-timeout", 0, "How long in-flight requests, including SSE streams, may take to finish on shutdown, REQUEST_TIMEOUT when 0")
+	previewsPtr := flag.Bool("previews", false, "Also serve the match preview job on POST /previews, for local runs with NOTIFY_SENDER=log")
+	flag.Parse()
+
+	logger := slog.New(log.NewCloudLoggingHandler())
+
+	cfg, err := config.Load(context.Background())
+	if err != nil {
+		logger.Error("error while loading config", slog.String(matchguru.ErrorMsgLogField, err.Error()))
+		os.Exit(1)
+	}
+	shutdownTimeout := *shutdownTimeoutPtr
+	if shutdownTimeout == 0 {
+		// streams with live updates last up to LIVE_UPDATES_DURATION, which is below REQUEST_TIMEOUT
+		shutdownTimeout = cfg.RequestTimeout.Duration
+	}
+	shutdownTracing, err := telemetry.Setup(context.Background(), cfg.ProjectID, cfg.Tracing)
+	if err != nil {
+		logger.Error("error while setting up tracing", slog.String(matchguru.ErrorMsgLogField, err.Error()))
+		os.Exit(1)
+	}
+	shutdownMetrics, metricsHandler, err := telemetry.SetupMetrics(context.Background(), cfg.ProjectID, cfg.Metrics)
+	if err != nil {
+		logger.Error("error while setting up metrics", slog.String(matchguru.ErrorMsgLogField, err.Error()))
+		os.Exit(1)
+	}
+	handler, err := matchguru.NewHandler(cfg)
+	if err != nil {
+		logger.Error("error while creating handler", slog.String(matchguru.ErrorMsgLogField, err.Error()))
+		os.Exit(1)
+	}
+
+	var ready atomic.Bool
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, _ *http.Request) {
+		if !ready.Load() {
+			http.Error(w, "Shutting Down", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	})
+	if metricsHandler != nil {
+		mux.Handle("GET /metrics", metricsHandler)
+	}
+	if *previewsPtr {
+		previews, err := matchguru.NewPreviewsHandler(context.Background(), cfg)
+		if err != nil {
+			logger.Error("error while creating previews handler", slog.String(matchguru.ErrorMsgLogField, err.Error()))
+			os.Exit(1)
+		}
+		mux.Handle("POST /previews", http.StripPrefix("/previews", previews))
+	}
+	mux.Handle("/", handler)
+
+	srv := &http.Server{
+		Addr:              net.JoinHostPort("", *portPtr),
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+		// no WriteTimeout, answers are streamed for as long as OpenAI generates them
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	errCh := make(chan error, 1)
+	go func() {
+		logger.Info("server listening", slog.String("addr", srv.Addr))
+		ready.Store(true)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		logger.Error("server failed", slog.String(matchguru.ErrorMsgLogField, err.Error()))
+		os.Exit(1)
+	case <-ctx.Done():
+	}
+
+	// stop accepting new requests and let the in-flight streams finish
+	ready.Store(false)
+	logger.Info("shutting down", slog.Duration("timeout", shutdownTimeout))
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		logger.Error("graceful shutdown failed", slog.String(matchguru.ErrorMsgLogField, err.Error()))
+		_ = srv.Close()
+	}
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		logger.Error("server failed", slog.String(matchguru.ErrorMsgLogField, err.Error()))
+	}
+	// spans and metrics of the last requests are still buffered
+	if err := shutdownTracing(shutdownCtx); err != nil {
+		logger.Error("error while flushing spans", slog.String(matchguru.ErrorMsgLogField, err.Error()))
+	}
+	if err := shutdownMetrics(shutdownCtx); err != nil {
+		logger.Error("error while flushing metrics", slog.String(matchguru.ErrorMsgLogField, err.Error()))
+	}
+	logger.Info("server stopped")
+}
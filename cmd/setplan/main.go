@@ -0,0 +1,68 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"path/filepath"
+	"strings"
+
+	firebase "firebase.google.com/go/v4"
+	"github.com/klipach/matchguru/config"
+	"github.com/klipach/matchguru/plan"
+	"google.golang.org/api/option"
+)
+
+// go run cmd/setplan/main.go -uid <uid> -plan pro
+// the plans are read from CONFIG_FILE and PLANS like the bot reads them, so configured plans can be set too
+func main() {
+	ctx := context.Background()
+	plans, err := config.LoadPlans()
+	if err != nil {
+		log.Fatalf("error loading plans: %v", err)
+	}
+	uidPtr := flag.String("uid", "", "User UID to set the plan for")
+	planPtr := flag.String("plan", "", "Plan name: "+strings.Join(plans.Names(), ", "))
+	flag.Parse()
+
+	if *uidPtr == "" {
+		log.Fatalf("Please provide a user UID using the -uid flag")
+	}
+	if !plans.Exists(*planPtr) {
+		log.Fatalf("Unknown plan %q, expected one of: %s", *planPtr, strings.Join(plans.Names(), ", "))
+	}
+
+	absPath, err := filepath.Abs("./service_account_key.json")
+	if err != nil {
+		log.Fatalf("failed to get absolute path: %v", err)
+	}
+	app, err := firebase.NewApp(ctx, nil, option.WithCredentialsFile(absPath))
+	if err != nil {
+		log.Fatalf("error initializing app: %v", err)
+	}
+
+	client, err := app.Auth(ctx)
+	if err != nil {
+		log.Fatalf("error getting Auth client: %v", err)
+	}
+
+	user, err := client.GetUser(ctx, *uidPtr)
+	if err != nil {
+		log.Fatalf("error getting user: %v", err)
+	}
+
+	// SetCustomUserClaims replaces all claims, so keep the ones set before
+	claims := user.CustomClaims
+	if claims == nil {
+		claims = map[string]any{}
+	}
+	claims[plan.Claim] = *planPtr
+
+	if err := client.SetCustomUserClaims(ctx, *uidPtr, claims); err != nil {
+		log.Fatalf("error setting custom claims: %v", err)
+	}
+
+	// claims are part of the ID token, the user gets the new plan after the token is refreshed
+	fmt.Printf("user %s is on the %s plan now\n", *uidPtr, *planPtr)
+}
@@ -2,97 +2,324 @@ package main
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
-	"io"
 	"log"
-	"net/http"
+	"net/url"
 	"os"
+	"slices"
+	"strconv"
 	"strings"
+	"time"
+
+	"github.com/klipach/matchguru/dictionary"
+	"github.com/klipach/matchguru/sport"
 )
 
-type TeamAPIResponse struct {
-	Data []struct {
-		ID   int    `json:"id"`
+// source is where the teams of a sport are fetched from.
+type source struct {
+	comment string
+	pageURL func(apiKey string, page int) string
+}
+
+var sources = map[sport.Sport]source{
+	sport.Football: {
+		comment: "generated by cmd/team from https://api.sportmonks.com/v3/football/teams?include=country;activeSeasons\nteam name should be in lowercase",
+		pageURL: func(_ string, page int) string {
+			return fmt.Sprintf("https://api.sportmonks.com/v3/football/teams?include=country;activeSeasons&per_page=50&page=%d", page)
+		},
+	},
+	// the cricket API is a separate v2 API authenticated with the api_token query parameter,
+	// its teams have no active seasons to filter by league with
+	sport.Cricket: {
+		comment: "generated by cmd/team -sport cricket from https://cricket.sportmonks.com/api/v2.0/teams?include=country\nteam name should be in lowercase",
+		pageURL: func(apiKey string, page int) string {
+			return "https://cricket.sportmonks.com/api/v2.0/teams?" + url.Values{"api_token": {apiKey}, "include": {"country"}, "page": {strconv.Itoa(page)}}.Encode()
+		},
+	},
+}
+
+type Team struct {
+	ID      int    `json:"id"`
+	Name    string `json:"name"`
+	Country struct {
 		Name string `json:"name"`
-	} `json:"data"`
-	Pagination struct {
-		CurrentPage int  `json:"current_page"`
-		HasMore     bool `json:"has_more"`
-	} `json:"pagination"`
+	} `json:"country"`
+	ActiveSeasons []Season `json:"activeseasons"`
+}
+
+type Season struct {
+	LeagueID int `json:"league_id"`
+}
+
+// checkpoint is the fetch progress saved after every page, so an interrupted run resumes where it stopped.
+// It is only resumed by a run fetching the same sport with the same API key, within the maximum age,
+// the filters are applied after the fetch and don't matter.
+type checkpoint struct {
+	StartedAt time.Time `json:"started_at"`
+	Sport     string    `json:"sport"`
+	// KeyHash tells the API keys apart without storing them, the teams a key sees depend on its plan
+	KeyHash  string `json:"key_hash"`
+	NextPage int    `json:"next_page"`
+	Teams    []Team `json:"teams"`
+}
+
+// collision is a name shared by more than one team, the lowest ID keeps it.
+type collision struct {
+	name  string
+	teams []Team
 }
 
-// SPORTMONKS_API_KEY=*** go run cmd/team/main.go > teams.txt
+// SPORTMONKS_API_KEY=*** go run cmd/team/main.go -diff
+// without -w or -diff the generated filter/team.go is printed to stdout,
+// -country and -league limit the fetched teams, teams already in filter/team.go are kept,
+// -sport cricket generates filter/cricket_team.go
 func main() {
+	writePtr := flag.Bool("w", false, "Write the result to the dictionary of the sport instead of stdout")
+	diffPtr := flag.Bool("diff", false, "Only print the added, removed and renamed teams against the dictionary of the sport, teams outside of the filters show as removed")
+	filterDirPtr := flag.String("filter-dir", "filter", "Directory of the filter package holding the dictionaries")
+	checkpointPtr := flag.String("checkpoint", "teams.checkpoint.json", "File to resume an interrupted fetch from, empty to disable")
+	checkpointAgePtr := flag.Duration("checkpoint-max-age", 24*time.Hour, "Older checkpoints are discarded, the teams they hold may be outdated")
+	countryPtr := flag.String("country", "", "Only teams of the country, such as England")
+	leaguePtr := flag.Int("league", 0, "Only teams playing in the current season of the league ID, football only")
+	sportPtr := flag.String("sport", string(sport.Default), "Sport of the teams, football or cricket")
+	flag.Parse()
+
+	sp, err := sport.Parse(*sportPtr)
+	if err != nil {
+		log.Fatal(err)
+	}
+	if sp != sport.Football && *leaguePtr != 0 {
+		log.Fatalf("-league filters by the active seasons only the football API returns")
+	}
 	apiKey := os.Getenv("SPORTMONKS_API_KEY")
 	ctx := context.Background()
-	teams, err := FetchTeams(ctx, apiKey)
+	teams, err := FetchTeams(ctx, apiKey, sp, *checkpointPtr, *checkpointAgePtr)
 	if err != nil {
 		log.Fatalf("Failed to fetch teams: %v", err)
 	}
-	// format teams as go map[string]int, convert names to lowercase
-	for name, id := range teams {
-		fmt.Printf("\"%s\": %d,\n", strings.ToLower(name), id)
+	teams = filterTeams(teams, *countryPtr, *leaguePtr)
+	log.Printf("teams after filtering: %d", len(teams))
+
+	names, collisions := buildNames(teams)
+	for _, c := range collisions {
+		log.Printf("name %q claimed by %s, kept %d", c.name, describe(c.teams), c.teams[0].ID)
 	}
-}
 
-// FetchTeams fetches all teams from the SportMonks API and returns them as a map
-// where the key is the team name and the value is the team ID
-func FetchTeams(ctx context.Context, apiKey string) (map[string]int, error) {
-	teams := make(map[string]int)
-	page := 1
+	file := dictionary.Teams(*filterDirPtr, sp)
+	existing, err := file.Read()
+	if err != nil {
+		log.Fatalf("Failed to read existing teams: %v", err)
+	}
 
-	for {
-		url := fmt.Sprintf("https://api.sportmonks.com/v3/football/teams?include=country&per_page=50&page=%d", page)
-		log.Printf("fetching page %d from URL: %s", page, url)
+	if *diffPtr {
+		fmt.Print(diff(existing, names))
+		return
+	}
 
-		req, err := http.NewRequestWithContext(
-			ctx,
-			http.MethodGet,
-			url,
-			http.NoBody,
-		)
-		if err != nil {
-			return nil, err
+	// names added by hand or by cmd/linkmine, and teams outside of the filters, are kept
+	for name, id := range existing {
+		if _, ok := names[name]; !ok && id != 0 {
+			names[name] = id
 		}
+	}
+	out, err := dictionary.Render(file.VarName, sources[sp].comment, names)
+	if err != nil {
+		log.Fatalf("Failed to render teams: %v", err)
+	}
+	if !*writePtr {
+		os.Stdout.Write(out)
+		return
+	}
+	if err := os.WriteFile(file.Path, out, 0o644); err != nil {
+		log.Fatalf("Failed to write teams: %v", err)
+	}
+}
 
-		req.Header.Add("Authorization", apiKey)
-		req.Header.Add("Content-Type", "application/json")
+// FetchTeams fetches all teams of the sport with their country, and active seasons for football, from the SportMonks API.
+// The progress is saved to checkpointPath after every page and the file is removed once all pages are fetched,
+// a checkpoint of another sport or API key, or older than maxAge, is discarded.
+func FetchTeams(ctx context.Context, apiKey string, sp sport.Sport, checkpointPath string, maxAge time.Duration) ([]Team, error) {
+	src := sources[sp]
+	fresh := checkpoint{StartedAt: time.Now().UTC(), Sport: string(sp), KeyHash: keyHash(apiKey), NextPage: 1}
+	cp, err := loadCheckpoint(checkpointPath, fresh, maxAge)
+	if err != nil {
+		return nil, err
+	}
+	if cp.NextPage > 1 {
+		log.Printf("resuming from page %d with %d teams", cp.NextPage, len(cp.Teams))
+	}
 
-		resp, err := http.DefaultClient.Do(req)
+	for page := cp.NextPage; ; page++ {
+		teamResponse, err := dictionary.FetchPage[Team](ctx, apiKey, src.pageURL(apiKey, page))
 		if err != nil {
-			return nil, err
+			return nil, fmt.Errorf("page %d: %w", page, err)
 		}
-		defer resp.Body.Close()
+		log.Printf("page %d: Got %d teams", page, len(teamResponse.Data))
+		cp.Teams = append(cp.Teams, teamResponse.Data...)
+		cp.NextPage = page + 1
 
-		if resp.StatusCode != http.StatusOK {
-			return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+		if !teamResponse.Pagination.HasMore || len(teamResponse.Data) == 0 {
+			break
 		}
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
+		if err := saveCheckpoint(checkpointPath, cp); err != nil {
 			return nil, err
 		}
+	}
 
-		var teamResponse TeamAPIResponse
-		if err := json.Unmarshal(body, &teamResponse); err != nil {
+	log.Printf("total teams fetched: %d", len(cp.Teams))
+	if checkpointPath != "" {
+		if err := os.Remove(checkpointPath); err != nil && !errors.Is(err, os.ErrNotExist) {
 			return nil, err
 		}
+	}
+	return cp.Teams, nil
+}
+
+// loadCheckpoint returns the checkpoint saved at path when it matches the sport and the key of fresh
+// and is not older than maxAge, fresh otherwise.
+func loadCheckpoint(path string, fresh checkpoint, maxAge time.Duration) (checkpoint, error) {
+	if path == "" {
+		return fresh, nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return fresh, nil
+	}
+	if err != nil {
+		return fresh, err
+	}
+	var cp checkpoint
+	if err := json.Unmarshal(data, &cp); err != nil {
+		return fresh, fmt.Errorf("%s: %w", path, err)
+	}
+	switch {
+	case cp.Sport != fresh.Sport || cp.KeyHash != fresh.KeyHash:
+		log.Printf("discarding %s, saved for another sport or API key", path)
+		return fresh, nil
+	case fresh.StartedAt.Sub(cp.StartedAt) > maxAge:
+		log.Printf("discarding %s, started at %s", path, cp.StartedAt.Format(time.RFC3339))
+		return fresh, nil
+	}
+	return cp, nil
+}
+
+// keyHash is a short fingerprint of the API key, the checkpoint file must not hold the key.
+func keyHash(apiKey string) string {
+	sum := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(sum[:8])
+}
+
+func saveCheckpoint(path string, cp checkpoint) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+// filterTeams keeps the teams of the country and the teams playing in the league, an empty filter keeps all.
+func filterTeams(teams []Team, country string, leagueID int) []Team {
+	return slices.DeleteFunc(slices.Clone(teams), func(t Team) bool {
+		if country != "" && !strings.EqualFold(t.Country.Name, country) {
+			return true
+		}
+		return leagueID != 0 && !slices.ContainsFunc(t.ActiveSeasons, func(s Season) bool { return s.LeagueID == leagueID })
+	})
+}
 
-		log.Printf("page %d: Got %d teams", page, len(teamResponse.Data))
+// buildNames maps the lowercase team names to team IDs. Teams sharing a name, such as the
+// men's and women's teams of a club, are reported as collisions and the lowest ID keeps the name,
+// so the output doesn't depend on the order of the API pages.
+func buildNames(teams []Team) (map[string]int, []collision) {
+	claims := map[string][]Team{}
+	for _, t := range teams {
+		name := strings.Join(strings.Fields(strings.ToLower(t.Name)), " ")
+		if name == "" || slices.ContainsFunc(claims[name], func(c Team) bool { return c.ID == t.ID }) {
+			continue
+		}
+		claims[name] = append(claims[name], t)
+	}
 
-		// Add teams to the map
-		for _, team := range teamResponse.Data {
-			teams[team.Name] = team.ID
+	names := make(map[string]int, len(claims))
+	var collisions []collision
+	for name, claimed := range claims {
+		slices.SortFunc(claimed, func(a, b Team) int { return a.ID - b.ID })
+		names[name] = claimed[0].ID
+		if len(claimed) > 1 {
+			collisions = append(collisions, collision{name: name, teams: claimed})
 		}
+	}
+	slices.SortFunc(collisions, func(a, b collision) int { return strings.Compare(a.name, b.name) })
+	return names, collisions
+}
 
-		if !teamResponse.Pagination.HasMore || len(teamResponse.Data) == 0 {
-			log.Printf("breaking loop: page=%d, dataLength=%d", page, len(teamResponse.Data))
-			break
+func describe(teams []Team) string {
+	parts := make([]string, 0, len(teams))
+	for _, t := range teams {
+		country := t.Country.Name
+		if country == "" {
+			country = "unknown country"
+		}
+		parts = append(parts, strconv.Itoa(t.ID)+" ("+country+")")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// diff describes the changes from the existing to the generated names, one per line and sorted:
+// "+" a team added, "-" a team removed, "~" a team renamed and "!" a name now pointing at another team.
+func diff(existing, generated map[string]int) string {
+	existingIDs := namesByID(existing)
+	generatedIDs := namesByID(generated)
+
+	var lines []string
+	for name, id := range generated {
+		oldID, ok := existing[name]
+		switch {
+		case ok && oldID != id:
+			lines = append(lines, fmt.Sprintf("! %q %d -> %d", name, oldID, id))
+		case ok:
+		case len(existingIDs[id]) > 0 && !slices.ContainsFunc(existingIDs[id], hasName(generated)):
+			lines = append(lines, fmt.Sprintf("~ %q -> %q %d", strings.Join(existingIDs[id], `", "`), name, id))
+		case len(existingIDs[id]) == 0:
+			lines = append(lines, fmt.Sprintf("+ %q %d", name, id))
 		}
-		page++
 	}
+	for name, id := range existing {
+		if _, ok := generated[name]; !ok && len(generatedIDs[id]) == 0 {
+			lines = append(lines, fmt.Sprintf("- %q %d", name, id))
+		}
+	}
+	slices.Sort(lines)
+
+	var sb strings.Builder
+	for _, l := range lines {
+		sb.WriteString(l + "\n")
+	}
+	return sb.String()
+}
 
-	log.Printf("total teams fetched: %d", len(teams))
-	return teams, nil
+func namesByID(names map[string]int) map[int][]string {
+	byID := make(map[int][]string, len(names))
+	for name, id := range names {
+		byID[id] = append(byID[id], name)
+	}
+	for _, n := range byID {
+		slices.Sort(n)
+	}
+	return byID
+}
+
+func hasName(names map[string]int) func(string) bool {
+	return func(name string) bool {
+		_, ok := names[name]
+		return ok
+	}
 }
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func team(id int, name, country string, leagueIDs ...int) Team {
+	t := Team{ID: id, Name: name}
+	t.Country.Name = country
+	for _, l := range leagueIDs {
+		t.ActiveSeasons = append(t.ActiveSeasons, Season{LeagueID: l})
+	}
+	return t
+}
+
+func TestBuildNames(t *testing.T) {
+	teams := []Team{
+		team(2891, "Arsenal", "Argentina"),
+		team(19, "Arsenal", "England"),
+		team(14, "Manchester  United", "England"),
+		team(14, "Manchester United", "England"),
+	}
+	names, collisions := buildNames(teams)
+	assert.Equal(t, map[string]int{"arsenal": 19, "manchester united": 14}, names)
+	assert.Equal(t, []collision{{name: "arsenal", teams: []Team{teams[1], teams[0]}}}, collisions)
+	assert.Equal(t, "19 (England), 2891 (Argentina)", describe(collisions[0].teams))
+}
+
+func TestFilterTeams(t *testing.T) {
+	teams := []Team{
+		team(19, "Arsenal", "England", 8, 24),
+		team(2891, "Arsenal", "Argentina", 636),
+		team(9, "Manchester City", "England", 8),
+	}
+	tests := []struct {
+		name     string
+		country  string
+		leagueID int
+		expected []int
+	}{
+		{name: "no filter", expected: []int{19, 2891, 9}},
+		{name: "country", country: "england", expected: []int{19, 9}},
+		{name: "league", leagueID: 24, expected: []int{19}},
+		{name: "country and league", country: "Argentina", leagueID: 8, expected: []int{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ids := []int{}
+			for _, team := range filterTeams(teams, tt.country, tt.leagueID) {
+				ids = append(ids, team.ID)
+			}
+			assert.Equal(t, tt.expected, ids)
+		})
+	}
+}
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name      string
+		existing  map[string]int
+		generated map[string]int
+		expected  string
+	}{
+		{
+			name:      "unchanged",
+			existing:  map[string]int{"arsenal": 19, "gunners": 19},
+			generated: map[string]int{"arsenal": 19},
+			expected:  "",
+		},
+		{
+			name:      "added and removed",
+			existing:  map[string]int{"arsenal": 19, "old club": 5},
+			generated: map[string]int{"arsenal": 19, "new club": 6},
+			expected:  "+ \"new club\" 6\n- \"old club\" 5\n",
+		},
+		{
+			name:      "renamed",
+			existing:  map[string]int{"fc internazionale": 2930},
+			generated: map[string]int{"inter": 2930},
+			expected:  "~ \"fc internazionale\" -> \"inter\" 2930\n",
+		},
+		{
+			name:      "name points at another team",
+			existing:  map[string]int{"arsenal": 2891},
+			generated: map[string]int{"arsenal": 19},
+			expected:  "! \"arsenal\" 2891 -> 19\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, diff(tt.existing, tt.generated))
+		})
+	}
+}
+
+func TestLoadCheckpoint(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	fresh := checkpoint{StartedAt: now, Sport: "football", KeyHash: keyHash("key"), NextPage: 1}
+	saved := checkpoint{StartedAt: now.Add(-time.Hour), Sport: "football", KeyHash: keyHash("key"), NextPage: 3, Teams: []Team{{ID: 1}}}
+
+	tests := []struct {
+		name    string
+		modify  func(cp *checkpoint)
+		resumed bool
+	}{
+		{name: "same sport and key", modify: func(*checkpoint) {}, resumed: true},
+		{name: "another sport", modify: func(cp *checkpoint) { cp.Sport = "cricket" }},
+		{name: "another key", modify: func(cp *checkpoint) { cp.KeyHash = keyHash("other") }},
+		{name: "too old", modify: func(cp *checkpoint) { cp.StartedAt = now.Add(-25 * time.Hour) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cp := saved
+			tt.modify(&cp)
+			data, err := json.Marshal(cp)
+			require.NoError(t, err)
+			path := filepath.Join(t.TempDir(), "teams.checkpoint.json")
+			require.NoError(t, os.WriteFile(path, data, 0o644))
+
+			loaded, err := loadCheckpoint(path, fresh, 24*time.Hour)
+			require.NoError(t, err)
+			if tt.resumed {
+				assert.Equal(t, cp, loaded)
+			} else {
+				assert.Equal(t, fresh, loaded)
+			}
+		})
+	}
+
+	loaded, err := loadCheckpoint(filepath.Join(t.TempDir(), "missing.json"), fresh, 24*time.Hour)
+	require.NoError(t, err)
+	assert.Equal(t, fresh, loaded)
+}
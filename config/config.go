@@ -0,0 +1,358 @@
+package config
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/compute/metadata"
+	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/notify"
+	"github.com/klipach/matchguru/plan"
+	"github.com/klipach/matchguru/telemetry"
+	"github.com/klipach/matchguru/usage"
+)
+
+const (
+	// FileEnv is the env variable pointing to an optional JSON config file,
+	// values set in env variables take precedence over the file.
+	FileEnv = "CONFIG_FILE"
+
+	defaultSportmonksBaseURL   = "https://api.sportmonks.com"
+	defaultCricketBaseURL      = "https://cricket.sportmonks.com"
+	defaultTitleModel          = "gpt-4o-mini"
+	defaultFixtureFetchTimeout = 3 * time.Second
+	defaultHistoryLoadTimeout  = 5 * time.Second
+	defaultLivePollInterval    = 20 * time.Second
+	defaultLiveUpdatesDuration = 15 * time.Minute
+	defaultRequestTimeout      = 20 * time.Minute
+	defaultPreviewModel        = "gpt-4o-mini"
+	defaultPreviewLeadTime     = 2 * time.Hour
+	defaultPreviewRunBudget    = 4 * time.Minute
+	defaultNotifySender        = notify.SenderFCM
+	defaultOddsSource          = OddsSourceSportmonks
+	defaultLogMaxBodyLength    = 500
+	defaultTraceSampleRatio    = 1
+)
+
+// sources of pre-match odds selected by ODDS_SOURCE
+const (
+	OddsSourceSportmonks = "sportmonks"
+	OddsSourceFake       = "fake" // the same odds for every game, for local runs without an odds subscription
+	OddsSourceNone       = "none"
+)
+
+// Config is the bot configuration, loaded once at cold start.
+type Config struct {
+	ProjectID           string                 `json:"project_id"`
+	OpenAIAPIKey        string                 `json:"openai_api_key"`
+	OpenAIPrices        map[string]usage.Price `json:"openai_prices"`
+	TitleModel          string                 `json:"title_model"` // cheap model naming chats
+	SportmonksAPIKey    string                 `json:"sportmonks_api_key"`
+	SportmonksBaseURL   string                 `json:"sportmonks_base_url"`
+	CricketBaseURL      string                 `json:"cricket_base_url"` // SportMonks cricket API, separate from the football one
+	FixtureFetchTimeout Duration               `json:"fixture_fetch_timeout"`
+	HistoryLoadTimeout  Duration               `json:"history_load_timeout"`
+	LivePollInterval    Duration               `json:"live_poll_interval"`    // how often the score of a game in play is polled
+	LiveUpdatesDuration Duration               `json:"live_updates_duration"` // how long score updates are pushed after an answer
+	RequestTimeout      Duration               `json:"request_timeout"`       // the function timeout, also how long cmd/server drains streams on shutdown
+	PreviewModel        string                 `json:"preview_model"`         // model writing the match previews of followed teams
+	PreviewLeadTime     Duration               `json:"preview_lead_time"`     // how long before kick-off previews are sent
+	PreviewRunBudget    Duration               `json:"preview_run_budget"`    // how long a preview run generates previews, below the function timeout
+	NotifySender        string                 `json:"notify_sender"`         // fcm, or log to run without FCM
+	OddsSource          string                 `json:"odds_source"`           // sportmonks, fake or none
+	Log                 log.Policy             `json:"log"`                   // redaction and sampling of logged bodies
+	LogLevel            slog.Level             `json:"log_level"`
+	Tracing             telemetry.Tracing      `json:"tracing"`
+	Metrics             telemetry.Metrics      `json:"metrics"`
+	Plans               plan.Plans             `json:"plans"` // a plan set here replaces the default plan of that name
+}
+
+// Duration is a time.Duration read from a string such as "3s".
+type Duration struct {
+	time.Duration
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = v
+	return nil
+}
+
+func defaults() *Config {
+	return &Config{
+		SportmonksBaseURL:   defaultSportmonksBaseURL,
+		CricketBaseURL:      defaultCricketBaseURL,
+		TitleModel:          defaultTitleModel,
+		FixtureFetchTimeout: Duration{defaultFixtureFetchTimeout},
+		HistoryLoadTimeout:  Duration{defaultHistoryLoadTimeout},
+		LivePollInterval:    Duration{defaultLivePollInterval},
+		LiveUpdatesDuration: Duration{defaultLiveUpdatesDuration},
+		RequestTimeout:      Duration{defaultRequestTimeout},
+		PreviewModel:        defaultPreviewModel,
+		PreviewLeadTime:     Duration{defaultPreviewLeadTime},
+		PreviewRunBudget:    Duration{defaultPreviewRunBudget},
+		NotifySender:        defaultNotifySender,
+		OddsSource:          defaultOddsSource,
+		Log:                 log.Policy{MaxBodyLength: defaultLogMaxBodyLength},
+		Tracing:             telemetry.Tracing{Exporter: telemetry.ExporterNone, SampleRatio: defaultTraceSampleRatio},
+		Metrics:             telemetry.Metrics{Exporter: telemetry.ExporterNone},
+		Plans:               plan.Defaults(),
+	}
+}
+
+// Load reads the config from the optional file and env variables, resolves
+// Secret Manager references and validates the result.
+func Load(ctx context.Context) (*Config, error) {
+	cfg := defaults()
+	if path := os.Getenv(FileEnv); path != "" {
+		if err := loadFile(cfg, path); err != nil {
+			return nil, err
+		}
+	}
+	if err := loadEnv(cfg, os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if cfg.ProjectID == "" && metadata.OnGCE() {
+		projectID, err := metadata.ProjectIDWithContext(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("resolving project ID: %w", err)
+		}
+		cfg.ProjectID = projectID
+	}
+
+	if err := resolveSecrets(ctx, cfg, accessSecret); err != nil {
+		return nil, err
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// LoadPlans reads the plans from the optional file and env variables like Load,
+// without requiring the rest of the config, for the tools setting users' plans.
+func LoadPlans() (plan.Plans, error) {
+	cfg := defaults()
+	if path := os.Getenv(FileEnv); path != "" {
+		if err := loadFile(cfg, path); err != nil {
+			return nil, err
+		}
+	}
+	if err := loadEnv(cfg, os.LookupEnv); err != nil {
+		return nil, err
+	}
+	return cfg.Plans, nil
+}
+
+func loadFile(cfg *Config, path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading config file: %w", err)
+	}
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return fmt.Errorf("decoding config file %s: %w", path, err)
+	}
+	return nil
+}
+
+func loadEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	stringVars := map[string]*string{
+		"PROJECT_ID":          &cfg.ProjectID,
+		"OPENAI_API_KEY":      &cfg.OpenAIAPIKey,
+		"SPORTMONKS_API_KEY":  &cfg.SportmonksAPIKey,
+		"SPORTMONKS_BASE_URL": &cfg.SportmonksBaseURL,
+		"CRICKET_BASE_URL":    &cfg.CricketBaseURL,
+		"TITLE_MODEL":         &cfg.TitleModel,
+		"PREVIEW_MODEL":       &cfg.PreviewModel,
+		"NOTIFY_SENDER":       &cfg.NotifySender,
+		"ODDS_SOURCE":         &cfg.OddsSource,
+		"TRACE_EXPORTER":      &cfg.Tracing.Exporter,
+		"METRICS_EXPORTER":    &cfg.Metrics.Exporter,
+	}
+	for name, field := range stringVars {
+		if v, ok := lookup(name); ok && v != "" {
+			*field = v
+		}
+	}
+
+	durationVars := map[string]*Duration{
+		"FIXTURE_FETCH_TIMEOUT": &cfg.FixtureFetchTimeout,
+		"HISTORY_LOAD_TIMEOUT":  &cfg.HistoryLoadTimeout,
+		"LIVE_POLL_INTERVAL":    &cfg.LivePollInterval,
+		"LIVE_UPDATES_DURATION": &cfg.LiveUpdatesDuration,
+		"REQUEST_TIMEOUT":       &cfg.RequestTimeout,
+		"PREVIEW_LEAD_TIME":     &cfg.PreviewLeadTime,
+		"PREVIEW_RUN_BUDGET":    &cfg.PreviewRunBudget,
+	}
+	for name, field := range durationVars {
+		if v, ok := lookup(name); ok && v != "" {
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return fmt.Errorf("invalid %s: %w", name, err)
+			}
+			field.Duration = d
+		}
+	}
+
+	if v, ok := lookup("LOG_MAX_BODY_LENGTH"); ok && v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return fmt.Errorf("invalid LOG_MAX_BODY_LENGTH: %w", err)
+		}
+		cfg.Log.MaxBodyLength = n
+	}
+	if v, ok := lookup("LOG_BODY_SAMPLE_RATE"); ok && v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid LOG_BODY_SAMPLE_RATE: %w", err)
+		}
+		cfg.Log.BodySampleRate = rate
+	}
+	if v, ok := lookup("LOG_HASH_USER_IDS"); ok && v != "" {
+		hash, err := strconv.ParseBool(v)
+		if err != nil {
+			return fmt.Errorf("invalid LOG_HASH_USER_IDS: %w", err)
+		}
+		cfg.Log.HashUserIDs = hash
+	}
+	// comma separated user IDs
+	if v, ok := lookup("LOG_DEBUG_USERS"); ok && v != "" {
+		cfg.Log.DebugUsers = nil
+		for _, uid := range strings.Split(v, ",") {
+			if uid = strings.TrimSpace(uid); uid != "" {
+				cfg.Log.DebugUsers = append(cfg.Log.DebugUsers, uid)
+			}
+		}
+	}
+
+	if v, ok := lookup("TRACE_SAMPLE_RATIO"); ok && v != "" {
+		ratio, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("invalid TRACE_SAMPLE_RATIO: %w", err)
+		}
+		cfg.Tracing.SampleRatio = ratio
+	}
+	if v, ok := lookup("LOG_LEVEL"); ok && v != "" {
+		if err := cfg.LogLevel.UnmarshalText([]byte(v)); err != nil {
+			return fmt.Errorf("invalid LOG_LEVEL: %w", err)
+		}
+	}
+
+	// formatted as {"model": {"prompt": 0.15, "completion": 0.6}}, USD per 1M tokens
+	if v, ok := lookup("OPENAI_PRICES"); ok && v != "" {
+		if err := json.Unmarshal([]byte(v), &cfg.OpenAIPrices); err != nil {
+			return fmt.Errorf("invalid OPENAI_PRICES: %w", err)
+		}
+	}
+	// formatted as the plans of the JSON file, {"pro": {"model": "gpt-4o-search-preview", "max_tokens": 2000, ...}}
+	if v, ok := lookup("PLANS"); ok && v != "" {
+		if err := json.Unmarshal([]byte(v), &cfg.Plans); err != nil {
+			return fmt.Errorf("invalid PLANS: %w", err)
+		}
+	}
+	return nil
+}
+
+func (c *Config) validate() error {
+	var errs []error
+	if c.OpenAIAPIKey == "" {
+		errs = append(errs, errors.New("OPENAI_API_KEY is required"))
+	}
+	if c.SportmonksAPIKey == "" {
+		errs = append(errs, errors.New("SPORTMONKS_API_KEY is required"))
+	}
+	if !strings.HasPrefix(c.SportmonksBaseURL, "http://") && !strings.HasPrefix(c.SportmonksBaseURL, "https://") {
+		errs = append(errs, fmt.Errorf("invalid SPORTMONKS_BASE_URL %q", c.SportmonksBaseURL))
+	}
+	if !strings.HasPrefix(c.CricketBaseURL, "http://") && !strings.HasPrefix(c.CricketBaseURL, "https://") {
+		errs = append(errs, fmt.Errorf("invalid CRICKET_BASE_URL %q", c.CricketBaseURL))
+	}
+	if c.FixtureFetchTimeout.Duration <= 0 {
+		errs = append(errs, errors.New("FIXTURE_FETCH_TIMEOUT must be positive"))
+	}
+	if c.HistoryLoadTimeout.Duration <= 0 {
+		errs = append(errs, errors.New("HISTORY_LOAD_TIMEOUT must be positive"))
+	}
+	if c.LivePollInterval.Duration <= 0 {
+		errs = append(errs, errors.New("LIVE_POLL_INTERVAL must be positive"))
+	}
+	if c.LiveUpdatesDuration.Duration < 0 {
+		errs = append(errs, errors.New("LIVE_UPDATES_DURATION must not be negative"))
+	}
+	if c.RequestTimeout.Duration <= 0 {
+		errs = append(errs, errors.New("REQUEST_TIMEOUT must be positive"))
+	} else if c.LiveUpdatesDuration.Duration >= c.RequestTimeout.Duration {
+		// the answer is generated before the live updates start
+		errs = append(errs, errors.New("LIVE_UPDATES_DURATION must be below REQUEST_TIMEOUT"))
+	}
+	if c.PreviewRunBudget.Duration <= 0 {
+		errs = append(errs, errors.New("PREVIEW_RUN_BUDGET must be positive"))
+	}
+	if c.PreviewLeadTime.Duration <= 0 {
+		errs = append(errs, errors.New("PREVIEW_LEAD_TIME must be positive"))
+	}
+	switch c.NotifySender {
+	case notify.SenderFCM, notify.SenderLog:
+	default:
+		errs = append(errs, fmt.Errorf("invalid NOTIFY_SENDER %q", c.NotifySender))
+	}
+	switch c.OddsSource {
+	case OddsSourceSportmonks, OddsSourceFake, OddsSourceNone:
+	default:
+		errs = append(errs, fmt.Errorf("invalid ODDS_SOURCE %q", c.OddsSource))
+	}
+	if c.Log.MaxBodyLength <= 0 {
+		errs = append(errs, errors.New("LOG_MAX_BODY_LENGTH must be positive"))
+	}
+	if c.Log.BodySampleRate < 0 || c.Log.BodySampleRate > 1 {
+		errs = append(errs, errors.New("LOG_BODY_SAMPLE_RATE must be between 0 and 1"))
+	}
+	switch c.Tracing.Exporter {
+	case telemetry.ExporterNone, telemetry.ExporterCloudTrace, telemetry.ExporterOTLP:
+	default:
+		errs = append(errs, fmt.Errorf("invalid TRACE_EXPORTER %q", c.Tracing.Exporter))
+	}
+	// users without a known plan get the free one
+	if !c.Plans.Exists(plan.Free) {
+		errs = append(errs, fmt.Errorf("PLANS must include %q", plan.Free))
+	}
+	for _, name := range c.Plans.Names() {
+		e := c.Plans[name]
+		if e.Model == "" {
+			errs = append(errs, fmt.Errorf("plan %q: model is required", name))
+		}
+		if e.MaxTokens <= 0 {
+			errs = append(errs, fmt.Errorf("plan %q: max_tokens must be positive", name))
+		}
+		if e.HistoryDepth < 0 || e.RateLimits.PerMinute < 0 || e.RateLimits.DailyMessages < 0 || e.RateLimits.DailyTokens < 0 {
+			errs = append(errs, fmt.Errorf("plan %q: history_depth and rate_limits must not be negative", name))
+		}
+	}
+	switch c.Metrics.Exporter {
+	case telemetry.ExporterNone, telemetry.ExporterCloudMonitoring, telemetry.ExporterPrometheus:
+	default:
+		errs = append(errs, fmt.Errorf("invalid METRICS_EXPORTER %q", c.Metrics.Exporter))
+	}
+	if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+		errs = append(errs, errors.New("TRACE_SAMPLE_RATIO must be between 0 and 1"))
+	}
+	if err := errors.Join(errs...); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	return nil
+}
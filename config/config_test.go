@@ -0,0 +1,196 @@
+package config
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/notify"
+	"github.com/klipach/matchguru/plan"
+	"github.com/klipach/matchguru/ratelimit"
+	"github.com/klipach/matchguru/telemetry"
+	"github.com/klipach/matchguru/usage"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lookupFrom(env map[string]string) func(string) (string, bool) {
+	return func(name string) (string, bool) {
+		v, ok := env[name]
+		return v, ok
+	}
+}
+
+func TestLoadEnv(t *testing.T) {
+	cfg := defaults()
+	err := loadEnv(cfg, lookupFrom(map[string]string{
+		"PROJECT_ID":            "project",
+		"OPENAI_API_KEY":        "openai-key",
+		"SPORTMONKS_API_KEY":    "sportmonks-key",
+		"FIXTURE_FETCH_TIMEOUT": "1s",
+		"OPENAI_PRICES":         `{"model": {"prompt": 1, "completion": 2}}`,
+		"LOG_BODY_SAMPLE_RATE":  "0.05",
+		"LOG_HASH_USER_IDS":     "true",
+		"LOG_DEBUG_USERS":       "uid1, uid2,",
+		"LOG_LEVEL":             "debug",
+		"TRACE_EXPORTER":        "otlp",
+		"TRACE_SAMPLE_RATIO":    "0.5",
+		"METRICS_EXPORTER":      "prometheus",
+		"PREVIEW_LEAD_TIME":     "90m",
+		"NOTIFY_SENDER":         "log",
+		"ODDS_SOURCE":           "fake",
+		"PLANS":                 `{"pro": {"model": "gpt-4o", "max_tokens": 4000, "rate_limits": {"per_minute": 30}}}`,
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, &Config{
+		ProjectID:           "project",
+		OpenAIAPIKey:        "openai-key",
+		OpenAIPrices:        map[string]usage.Price{"model": {Prompt: 1, Completion: 2}},
+		SportmonksAPIKey:    "sportmonks-key",
+		SportmonksBaseURL:   defaultSportmonksBaseURL,
+		CricketBaseURL:      defaultCricketBaseURL,
+		TitleModel:          defaultTitleModel,
+		FixtureFetchTimeout: Duration{time.Second},
+		HistoryLoadTimeout:  Duration{defaultHistoryLoadTimeout},
+		LivePollInterval:    Duration{defaultLivePollInterval},
+		LiveUpdatesDuration: Duration{defaultLiveUpdatesDuration},
+		RequestTimeout:      Duration{defaultRequestTimeout},
+		PreviewModel:        defaultPreviewModel,
+		PreviewLeadTime:     Duration{90 * time.Minute},
+		PreviewRunBudget:    Duration{defaultPreviewRunBudget},
+		NotifySender:        "log",
+		OddsSource:          OddsSourceFake,
+		Log: log.Policy{
+			MaxBodyLength:  defaultLogMaxBodyLength,
+			BodySampleRate: 0.05,
+			HashUserIDs:    true,
+			DebugUsers:     []string{"uid1", "uid2"},
+		},
+		LogLevel: slog.LevelDebug,
+		Tracing:  telemetry.Tracing{Exporter: telemetry.ExporterOTLP, SampleRatio: 0.5},
+		Metrics:  telemetry.Metrics{Exporter: telemetry.ExporterPrometheus},
+		Plans: plan.Plans{
+			plan.Free: plan.Defaults()[plan.Free],
+			plan.Pro:  {Model: "gpt-4o", MaxTokens: 4000, RateLimits: ratelimit.Limits{PerMinute: 30}},
+		},
+	}, cfg)
+	assert.NoError(t, cfg.validate())
+
+	assert.Error(t, loadEnv(defaults(), lookupFrom(map[string]string{"HISTORY_LOAD_TIMEOUT": "soon"})))
+	assert.Error(t, loadEnv(defaults(), lookupFrom(map[string]string{"OPENAI_PRICES": "{"})))
+	assert.Error(t, loadEnv(defaults(), lookupFrom(map[string]string{"LOG_HASH_USER_IDS": "maybe"})))
+	assert.Error(t, loadEnv(defaults(), lookupFrom(map[string]string{"LOG_LEVEL": "verbose"})))
+	assert.Error(t, loadEnv(defaults(), lookupFrom(map[string]string{"PLANS": `{"pro": {"max_tokens": "many"}}`})))
+}
+
+func TestLoadFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"openai_api_key": "file-key",
+		"sportmonks_base_url": "http://localhost:9000",
+		"history_load_timeout": "2s",
+		"log_level": "WARN",
+		"plans": {"gold": {"model": "gpt-4o", "max_tokens": 3000, "betting_analysis": true}}
+	}`), 0o600))
+
+	cfg := defaults()
+	require.NoError(t, loadFile(cfg, path))
+	assert.Equal(t, "file-key", cfg.OpenAIAPIKey)
+	assert.Equal(t, "http://localhost:9000", cfg.SportmonksBaseURL)
+	assert.Equal(t, 2*time.Second, cfg.HistoryLoadTimeout.Duration)
+	assert.Equal(t, defaultFixtureFetchTimeout, cfg.FixtureFetchTimeout.Duration)
+	assert.Equal(t, slog.LevelWarn, cfg.LogLevel)
+	// added to the default plans
+	assert.Equal(t, []string{"free", "gold", "pro"}, cfg.Plans.Names())
+	assert.Equal(t, plan.Entitlements{Model: "gpt-4o", MaxTokens: 3000, BettingAnalysis: true}, cfg.Plans["gold"])
+
+	// env variables take precedence over the file
+	require.NoError(t, loadEnv(cfg, lookupFrom(map[string]string{"OPENAI_API_KEY": "env-key"})))
+	assert.Equal(t, "env-key", cfg.OpenAIAPIKey)
+
+	assert.Error(t, loadFile(defaults(), filepath.Join(t.TempDir(), "missing.json")))
+}
+
+func TestValidate(t *testing.T) {
+	cfg := defaults()
+	err := cfg.validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "OPENAI_API_KEY is required")
+	assert.Contains(t, err.Error(), "SPORTMONKS_API_KEY is required")
+
+	cfg.OpenAIAPIKey = "key"
+	cfg.SportmonksAPIKey = "key"
+	cfg.SportmonksBaseURL = "api.sportmonks.com"
+	assert.ErrorContains(t, cfg.validate(), "invalid SPORTMONKS_BASE_URL")
+
+	cfg.SportmonksBaseURL = defaultSportmonksBaseURL
+	cfg.Log.BodySampleRate = 2
+	assert.ErrorContains(t, cfg.validate(), "LOG_BODY_SAMPLE_RATE must be between 0 and 1")
+
+	cfg.Log.BodySampleRate = 0
+	cfg.Tracing.Exporter = "jaeger"
+	assert.ErrorContains(t, cfg.validate(), `invalid TRACE_EXPORTER "jaeger"`)
+
+	cfg.Tracing.Exporter = telemetry.ExporterNone
+	cfg.NotifySender = "sms"
+	assert.ErrorContains(t, cfg.validate(), `invalid NOTIFY_SENDER "sms"`)
+
+	cfg.NotifySender = notify.SenderFCM
+	cfg.OddsSource = "oddsportal"
+	assert.ErrorContains(t, cfg.validate(), `invalid ODDS_SOURCE "oddsportal"`)
+
+	cfg.OddsSource = OddsSourceNone
+	cfg.LiveUpdatesDuration = Duration{time.Hour}
+	assert.ErrorContains(t, cfg.validate(), "LIVE_UPDATES_DURATION must be below REQUEST_TIMEOUT")
+
+	cfg.LiveUpdatesDuration = Duration{defaultLiveUpdatesDuration}
+	cfg.Plans = plan.Plans{plan.Pro: {Model: "gpt-4o"}}
+	err = cfg.validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `PLANS must include "free"`)
+	assert.Contains(t, err.Error(), `plan "pro": max_tokens must be positive`)
+
+	cfg.Plans = plan.Plans{plan.Free: {MaxTokens: 1000, HistoryDepth: -1}}
+	err = cfg.validate()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `plan "free": model is required`)
+	assert.Contains(t, err.Error(), `plan "free": history_depth and rate_limits must not be negative`)
+
+	cfg.Plans = plan.Defaults()
+	assert.NoError(t, cfg.validate())
+}
+
+func TestResolveSecrets(t *testing.T) {
+	access := func(_ context.Context, name string) (string, error) {
+		switch name {
+		case "projects/project/secrets/openai/versions/latest":
+			return "openai-secret\n", nil
+		case "projects/other/secrets/sportmonks/versions/2":
+			return "sportmonks-secret", nil
+		}
+		return "", errors.New("not found")
+	}
+
+	cfg := &Config{
+		ProjectID:        "project",
+		OpenAIAPIKey:     "sm://openai",
+		SportmonksAPIKey: "sm://projects/other/secrets/sportmonks/versions/2",
+	}
+	require.NoError(t, resolveSecrets(context.Background(), cfg, access))
+	assert.Equal(t, "openai-secret", cfg.OpenAIAPIKey)
+	assert.Equal(t, "sportmonks-secret", cfg.SportmonksAPIKey)
+
+	// plain values are kept as is
+	cfg = &Config{OpenAIAPIKey: "plain"}
+	require.NoError(t, resolveSecrets(context.Background(), cfg, access))
+	assert.Equal(t, "plain", cfg.OpenAIAPIKey)
+
+	assert.Error(t, resolveSecrets(context.Background(), &Config{ProjectID: "project", OpenAIAPIKey: "sm://missing"}, access))
+	assert.Error(t, resolveSecrets(context.Background(), &Config{OpenAIAPIKey: "sm://openai"}, access))
+	assert.Error(t, resolveSecrets(context.Background(), &Config{ProjectID: "project", OpenAIAPIKey: "sm://a/b"}, access))
+}
@@ -0,0 +1,65 @@
+package config
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"google.golang.org/api/secretmanager/v1"
+)
+
+// secretPrefix marks a value as a Secret Manager reference, either a full version name
+// sm://projects/PROJECT/secrets/NAME/versions/VERSION or a short sm://NAME for the latest
+// version of a secret in the configured project.
+const secretPrefix = "sm://"
+
+type secretAccessor func(ctx context.Context, name string) (string, error)
+
+func resolveSecrets(ctx context.Context, cfg *Config, access secretAccessor) error {
+	for _, field := range []*string{&cfg.OpenAIAPIKey, &cfg.SportmonksAPIKey} {
+		if !strings.HasPrefix(*field, secretPrefix) {
+			continue
+		}
+		name, err := secretVersionName(cfg.ProjectID, *field)
+		if err != nil {
+			return err
+		}
+		value, err := access(ctx, name)
+		if err != nil {
+			return fmt.Errorf("accessing secret %s: %w", name, err)
+		}
+		*field = strings.TrimSpace(value)
+	}
+	return nil
+}
+
+func secretVersionName(projectID, ref string) (string, error) {
+	name := strings.TrimPrefix(ref, secretPrefix)
+	if strings.HasPrefix(name, "projects/") {
+		return name, nil
+	}
+	if name == "" || strings.Contains(name, "/") {
+		return "", fmt.Errorf("invalid secret reference %q", ref)
+	}
+	if projectID == "" {
+		return "", fmt.Errorf("secret reference %q requires PROJECT_ID", ref)
+	}
+	return "projects/" + projectID + "/secrets/" + name + "/versions/latest", nil
+}
+
+func accessSecret(ctx context.Context, name string) (string, error) {
+	svc, err := secretmanager.NewService(ctx)
+	if err != nil {
+		return "", err
+	}
+	resp, err := svc.Projects.Secrets.Versions.Access(name).Context(ctx).Do()
+	if err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
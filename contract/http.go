@@ -1,12 +1,192 @@
 package contract
 
+import "time"
+
+// names of the SSE events sent besides the default "message" event carrying BotResponse
+const (
+	EventThinking = "thinking"
+	EventError    = "error"
+	EventTitle    = "title"
+	EventScore    = "score" // pushed after the answer while the game is in play, if live updates are requested
+	EventOdds     = "odds"  // sent before the answer for a game with pre-match odds, on plans with betting analysis
+)
+
+// error codes of the error event
+const (
+	ErrorCodeConflict = "conflict" // the chat state doesn't allow the operation, e.g. nothing to regenerate
+)
+
+// BotRequest is a new message for POST /, the same body is used by
+// POST /chats/{chatID}/edit (with the edited message) and POST /chats/{chatID}/regenerate (without message).
+// Answers to new messages are stored by the client, edited and regenerated answers are stored by the bot.
 type BotRequest struct {
 	Message  string `json:"message"`
 	ChatID   int    `json:"chat_id"`
 	GameID   int    `json:"game_id"`
 	Timezone string `json:"timezone"`
+	Sport    string `json:"sport,omitempty"` // football or cricket, football when empty
+	// Language of the answer as a BCP 47 tag such as "uk", when empty the user's preference or else Accept-Language
+	Language string `json:"language,omitempty"`
+	// LiveUpdates keeps the stream open after the answer and pushes score events while the game is in play
+	LiveUpdates bool `json:"live_updates,omitempty"`
 }
 
 type BotResponse struct {
 	Response string `json:"response"`
 }
+
+type BotStatus struct {
+	Status string `json:"status"`
+}
+
+type ChatTitle struct {
+	ChatID int    `json:"chat_id"`
+	Title  string `json:"title"`
+}
+
+// LiveScore is the score of a game in play, sent when it changes.
+type LiveScore struct {
+	GameID    int    `json:"game_id"`
+	State     string `json:"state"`   // SportMonks state such as INPLAY_2ND_HALF, FT once the game is over
+	InPlay    bool   `json:"in_play"` // false in the last event of the stream
+	HomeScore int    `json:"home_score"`
+	AwayScore int    `json:"away_score"`
+	// LastEvent is the latest goal, card or substitution, such as "58' Goal: Cole Palmer, Chelsea, 1-1"
+	LastEvent string `json:"last_event,omitempty"`
+}
+
+// Odds are the pre-match prices of the full time result of a game, averaged across bookmakers.
+type Odds struct {
+	GameID     int           `json:"game_id"`
+	Market     string        `json:"market"`
+	Format     string        `json:"format"` // decimal, fractional or american, as set in the user's preferences
+	Bookmakers int           `json:"bookmakers"`
+	Margin     float64       `json:"margin"` // bookmaker margin of the average prices, 0.05 is 5%
+	Outcomes   []OddsOutcome `json:"outcomes"`
+}
+
+type OddsOutcome struct {
+	Label              string  `json:"label"` // Home, Draw or Away
+	Price              string  `json:"price"` // average price in the format
+	Decimal            float64 `json:"decimal"`
+	ImpliedProbability float64 `json:"implied_probability"` // of the average price, margin included
+	FairProbability    float64 `json:"fair_probability"`    // margin removed, the outcomes add up to 1
+	BestPrice          string  `json:"best_price"`
+	BestBookmaker      string  `json:"best_bookmaker,omitempty"`
+}
+
+type BotError struct {
+	Error      string `json:"error"`
+	Message    string `json:"message,omitempty"` // shown to the user, in the language of the request
+	Code       string `json:"code,omitempty"`
+	RetryAfter int    `json:"retry_after,omitempty"` // seconds
+}
+
+type UsageTotals struct {
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+type ChatUsage struct {
+	ChatID int `json:"chat_id"`
+	UsageTotals
+}
+
+type DayUsage struct {
+	Day string `json:"day"`
+	UsageTotals
+}
+
+type UsageResponse struct {
+	From  string      `json:"from"`
+	To    string      `json:"to"`
+	Total UsageTotals `json:"total"`
+	Chats []ChatUsage `json:"chats"`
+	Days  []DayUsage  `json:"days"`
+}
+
+type ChatMessage struct {
+	From       string   `json:"from"`
+	Message    string   `json:"message"`
+	Alternates []string `json:"alternates,omitempty"` // prior versions, oldest first
+}
+
+type Chat struct {
+	ChatID       int          `json:"chat_id"`
+	Title        string       `json:"title"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+	MessageCount int          `json:"message_count"`
+	LastMessage  *ChatMessage `json:"last_message,omitempty"`
+}
+
+type ChatRequest struct {
+	Title string `json:"title"`
+}
+
+type ChatsResponse struct {
+	Chats []Chat `json:"chats"`
+}
+
+type MessagesResponse struct {
+	Messages []ChatMessage `json:"messages"`
+	Offset   int           `json:"offset"`
+	Total    int           `json:"total"`
+}
+
+// FeedbackRequest rates a bot message, addressed by its index in GET /chats/{chatID}/messages.
+type FeedbackRequest struct {
+	ChatID       int    `json:"chat_id"`
+	MessageIndex int    `json:"message_index"`
+	Rating       string `json:"rating"`             // "up" or "down"
+	Category     string `json:"category,omitempty"` // required for "down": wrong_info, outdated, formatting, off_topic, other
+	Comment      string `json:"comment,omitempty"`
+	GameID       int    `json:"game_id,omitempty"` // game the chat is about, if any
+	Sport        string `json:"sport,omitempty"`   // sport of the chat, football when empty
+}
+
+// AccountExport is everything stored about a user, returned by GET /account/export.
+type AccountExport struct {
+	UserID      string                      `json:"user_id"`
+	ExportedAt  time.Time                   `json:"exported_at"`
+	Profile     map[string]any              `json:"profile"`     // user document, including chats
+	Collections map[string][]map[string]any `json:"collections"` // feedback, usage, quotas, ...
+}
+
+// Follow is a followed team or league, the ID is the one of the app's team and league pages.
+type Follow struct {
+	Type      string    `json:"type"` // "team" or "league"
+	ID        int       `json:"id"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type FollowsResponse struct {
+	Follows []Follow `json:"follows"`
+}
+
+// DeviceRequest registers the Firebase Cloud Messaging token of an app install for match previews.
+type DeviceRequest struct {
+	Token string `json:"token"`
+}
+
+// PreviewRun is the result of a run of the match preview job.
+type PreviewRun struct {
+	Fixtures int `json:"fixtures"` // upcoming fixtures with followers
+	Previews int `json:"previews"` // previews generated, fixtures previewed by an earlier run are skipped
+	Deferred int `json:"deferred"` // fixtures left to the next run once the run budget is spent
+	Sent     int `json:"sent"`     // notifications delivered
+}
+
+// Preferences tailor the answers to the user, read and replaced with GET and PUT /preferences.
+// Empty fields are not set and leave the choice to the bot.
+type Preferences struct {
+	FavouriteTeams []string `json:"favourite_teams,omitempty"`
+	Language       string   `json:"language,omitempty"`       // BCP 47 tag such as "en" or "pt-BR"
+	RiskTolerance  string   `json:"risk_tolerance,omitempty"` // "low", "medium" or "high"
+	OddsFormat     string   `json:"odds_format,omitempty"`    // "decimal", "fractional" or "american"
+	Verbosity      string   `json:"verbosity,omitempty"`      // "brief", "normal" or "detailed"
+	Units          string   `json:"units,omitempty"`          // "metric" or "imperial"
+}
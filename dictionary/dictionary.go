@@ -0,0 +1,217 @@
+package dictionary
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/klipach/matchguru/sport"
+)
+
+// File is a Go source file of the filter package holding a name to ID map literal,
+// the link resolver looks the lowercase English names of the links up in it.
+type File struct {
+	Path    string
+	VarName string
+}
+
+// Leagues is the league dictionary of the sport in the filter package directory dir,
+// filter/league.go for football and filter/{sport}_league.go for the sports added after it.
+func Leagues(dir string, s sport.Sport) File {
+	return sportFile(dir, s, "league")
+}
+
+// Teams is the team dictionary of the sport in the filter package directory dir,
+// filter/team.go for football and filter/{sport}_team.go for the sports added after it.
+func Teams(dir string, s sport.Sport) File {
+	return sportFile(dir, s, "team")
+}
+
+func sportFile(dir string, s sport.Sport, entity string) File {
+	if s == sport.Football {
+		return File{Path: filepath.Join(dir, entity+".go"), VarName: entity + "NameToID"}
+	}
+	return File{
+		Path:    filepath.Join(dir, string(s)+"_"+entity+".go"),
+		VarName: string(s) + strings.ToUpper(entity[:1]) + entity[1:] + "NameToID",
+	}
+}
+
+// Add writes the mapping of the lowercase name into the file.
+func (f File) Add(name string, id int) error {
+	src, err := os.ReadFile(f.Path)
+	if err != nil {
+		return err
+	}
+	updated, err := AddMapping(src, f.VarName, name, id)
+	if err != nil {
+		return fmt.Errorf("%s: %w", f.Path, err)
+	}
+	return os.WriteFile(f.Path, updated, 0o644)
+}
+
+// Read returns the mappings of the file.
+func (f File) Read() (map[string]int, error) {
+	src, err := os.ReadFile(f.Path)
+	if err != nil {
+		return nil, err
+	}
+	m, err := ReadMapping(src, f.VarName)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", f.Path, err)
+	}
+	return m, nil
+}
+
+// Render returns the source of a filter package file holding the mappings, sorted by name
+// so that regenerating the file gives readable diffs. The comment goes above the map.
+func Render(varName, comment string, mappings map[string]int) ([]byte, error) {
+	names := make([]string, 0, len(mappings))
+	for name := range mappings {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	var buf bytes.Buffer
+	buf.WriteString("package filter\n\n")
+	for _, line := range strings.Split(comment, "\n") {
+		buf.WriteString("// " + line + "\n")
+	}
+	buf.WriteString("var " + varName + " = map[string]int{\n")
+	for _, name := range names {
+		buf.WriteString("\t" + strconv.Quote(name) + ": " + strconv.Itoa(mappings[name]) + ",\n")
+	}
+	buf.WriteString("}\n")
+	return format.Source(buf.Bytes())
+}
+
+// ReadMapping returns the entries of the map literal assigned to varName.
+func ReadMapping(src []byte, varName string) (map[string]int, error) {
+	file, err := parser.ParseFile(token.NewFileSet(), "", src, 0)
+	if err != nil {
+		return nil, err
+	}
+	lit := findMapLiteral(file, varName)
+	if lit == nil {
+		return nil, fmt.Errorf("map literal %s not found", varName)
+	}
+	m := make(map[string]int, len(lit.Elts))
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, keyOK := kv.Key.(*ast.BasicLit)
+		value, valueOK := kv.Value.(*ast.BasicLit)
+		if !keyOK || !valueOK || key.Kind != token.STRING || value.Kind != token.INT {
+			return nil, fmt.Errorf("unexpected entry at offset %d", kv.Pos())
+		}
+		name, err := strconv.Unquote(key.Value)
+		if err != nil {
+			return nil, err
+		}
+		id, err := strconv.Atoi(value.Value)
+		if err != nil {
+			return nil, err
+		}
+		m[name] = id
+	}
+	return m, nil
+}
+
+// AddMapping sets name to id in the map literal assigned to varName. An existing key,
+// such as a league mapped to 0, gets the new value and loses its trailing comment.
+// The result is gofmt-ed, so the map stays aligned.
+func AddMapping(src []byte, varName, name string, id int) ([]byte, error) {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "", src, parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+	lit := findMapLiteral(file, varName)
+	if lit == nil {
+		return nil, fmt.Errorf("map literal %s not found", varName)
+	}
+	offset := func(p token.Pos) int { return fset.Position(p).Offset }
+	value := []byte(strconv.Itoa(id))
+
+	var out []byte
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+		key, ok := kv.Key.(*ast.BasicLit)
+		if !ok || key.Kind != token.STRING {
+			continue
+		}
+		if k, err := strconv.Unquote(key.Value); err != nil || k != name {
+			continue
+		}
+		end := offset(kv.Value.End())
+		if c := trailingComment(fset, file, kv); c != nil {
+			end = offset(c.End())
+		}
+		out = append(out, src[:offset(kv.Value.Pos())]...)
+		out = append(out, value...)
+		out = append(out, ',')
+		// the comma following the value is replaced with the value
+		rest := src[end:]
+		if end == offset(kv.Value.End()) {
+			rest = bytes.TrimPrefix(rest, []byte(","))
+		}
+		out = append(out, rest...)
+		return format.Source(out)
+	}
+
+	// a new key goes on its own line before the closing brace
+	rbrace := offset(lit.Rbrace)
+	lineStart := bytes.LastIndexByte(src[:rbrace], '\n') + 1
+	out = append(out, src[:lineStart]...)
+	out = append(out, fmt.Sprintf("\t%s: %s,\n", strconv.Quote(name), value)...)
+	out = append(out, src[lineStart:]...)
+	return format.Source(out)
+}
+
+func findMapLiteral(file *ast.File, varName string) *ast.CompositeLit {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			vs, ok := spec.(*ast.ValueSpec)
+			if !ok {
+				continue
+			}
+			for i, ident := range vs.Names {
+				if ident.Name != varName || i >= len(vs.Values) {
+					continue
+				}
+				if lit, ok := vs.Values[i].(*ast.CompositeLit); ok {
+					return lit
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// trailingComment returns the comment on the same line after the map entry.
+func trailingComment(fset *token.FileSet, file *ast.File, kv *ast.KeyValueExpr) *ast.CommentGroup {
+	line := fset.Position(kv.End()).Line
+	for _, c := range file.Comments {
+		if c.Pos() > kv.End() && fset.Position(c.Pos()).Line == line {
+			return c
+		}
+	}
+	return nil
+}
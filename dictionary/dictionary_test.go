@@ -0,0 +1,128 @@
+package dictionary
+
+import (
+	"testing"
+
+	"github.com/klipach/matchguru/sport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const leagueSource = `package filter
+
+// source https://api.sportmonks.com/v3/football/leagues
+var leagueNameToID = map[string]int{ // league name should be in lowercase
+	"premier league":         8,
+	"russian premier league": 0, // 486 no such country
+}
+`
+
+func TestAddMapping(t *testing.T) {
+	tests := []struct {
+		name     string
+		key      string
+		id       int
+		expected string
+	}{
+		{
+			name: "new key",
+			key:  "scottish premiership",
+			id:   501,
+			expected: `package filter
+
+// source https://api.sportmonks.com/v3/football/leagues
+var leagueNameToID = map[string]int{ // league name should be in lowercase
+	"premier league":         8,
+	"russian premier league": 0, // 486 no such country
+	"scottish premiership":   501,
+}
+`,
+		},
+		{
+			name: "unmapped key",
+			key:  "russian premier league",
+			id:   486,
+			expected: `package filter
+
+// source https://api.sportmonks.com/v3/football/leagues
+var leagueNameToID = map[string]int{ // league name should be in lowercase
+	"premier league":         8,
+	"russian premier league": 486,
+}
+`,
+		},
+		{
+			name: "existing key",
+			key:  "premier league",
+			id:   9,
+			expected: `package filter
+
+// source https://api.sportmonks.com/v3/football/leagues
+var leagueNameToID = map[string]int{ // league name should be in lowercase
+	"premier league":         9,
+	"russian premier league": 0, // 486 no such country
+}
+`,
+		},
+		{
+			name: "quoted key",
+			key:  `club "atlético"`,
+			id:   7,
+			expected: `package filter
+
+// source https://api.sportmonks.com/v3/football/leagues
+var leagueNameToID = map[string]int{ // league name should be in lowercase
+	"premier league":         8,
+	"russian premier league": 0, // 486 no such country
+	"club \"atlético\"":      7,
+}
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := AddMapping([]byte(leagueSource), "leagueNameToID", tt.key, tt.id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, string(out))
+		})
+	}
+
+	_, err := AddMapping([]byte(leagueSource), "teamNameToID", "zenit", 1)
+	assert.Error(t, err)
+}
+
+func TestReadMapping(t *testing.T) {
+	m, err := ReadMapping([]byte(leagueSource), "leagueNameToID")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"premier league": 8, "russian premier league": 0}, m)
+
+	_, err = ReadMapping([]byte(leagueSource), "teamNameToID")
+	assert.Error(t, err)
+}
+
+func TestRender(t *testing.T) {
+	src, err := Render("leagueNameToID", "source https://api.sportmonks.com/v3/football/leagues", map[string]int{
+		"russian premier league": 486,
+		"premier league":         8,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, `package filter
+
+// source https://api.sportmonks.com/v3/football/leagues
+var leagueNameToID = map[string]int{
+	"premier league":         8,
+	"russian premier league": 486,
+}
+`, string(src))
+
+	m, err := ReadMapping(src, "leagueNameToID")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"premier league": 8, "russian premier league": 486}, m)
+}
+
+func TestSportFiles(t *testing.T) {
+	assert.Equal(t, File{Path: "filter/league.go", VarName: "leagueNameToID"}, Leagues("filter", sport.Football))
+	assert.Equal(t, File{Path: "filter/team.go", VarName: "teamNameToID"}, Teams("filter", sport.Football))
+	assert.Equal(t, File{Path: "filter/cricket_league.go", VarName: "cricketLeagueNameToID"}, Leagues("filter", sport.Cricket))
+	assert.Equal(t, File{Path: "filter/cricket_team.go", VarName: "cricketTeamNameToID"}, Teams("filter", sport.Cricket))
+}
@@ -0,0 +1,110 @@
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/klipach/matchguru/telemetry"
+)
+
+const maxAttempts = 5
+
+// initialBackoff is doubled after every failed attempt
+var initialBackoff = time.Second
+
+// Page is a page of a SportMonks list endpoint, such as the teams or the leagues the dictionaries are generated from.
+type Page[T any] struct {
+	Data       []T `json:"data"`
+	Pagination struct {
+		CurrentPage int  `json:"current_page"`
+		HasMore     bool `json:"has_more"`
+	} `json:"pagination"`
+}
+
+// StatusError is a response with an unexpected status code.
+type StatusError struct {
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("unexpected status code: %d", e.Code)
+}
+
+// FetchPage fetches a page of a SportMonks list endpoint. Network errors, rate limiting and server errors
+// are retried with exponential backoff.
+func FetchPage[T any](ctx context.Context, apiKey, url string) (*Page[T], error) {
+	backoff := initialBackoff
+	for attempt := 1; ; attempt++ {
+		page, err := fetchPage[T](ctx, apiKey, url)
+		if err == nil || !retryable(err) || attempt == maxAttempts {
+			return page, err
+		}
+		log.Printf("%s: attempt %d failed, retrying in %s: %v", telemetry.RedactURL(url), attempt, backoff, err)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+	}
+}
+
+// retryable reports whether the error is transient: rate limiting, a server error or a network failure.
+// Client errors, malformed responses and the cancellation of ctx are not retried.
+func retryable(err error) bool {
+	var se *StatusError
+	if errors.As(err, &se) {
+		return se.Code == http.StatusTooManyRequests || se.Code >= http.StatusInternalServerError
+	}
+	// context errors satisfy net.Error as well
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	// every error of the client is a *url.Error, which is a net.Error whatever it wraps
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		err = urlErr.Err
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+func fetchPage[T any](ctx context.Context, apiKey, url string) (*Page[T], error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add("Authorization", apiKey)
+	req.Header.Add("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		// the cricket API key is a query parameter
+		return nil, telemetry.RedactError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{Code: resp.StatusCode}
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var page Page[T]
+	if err := json.Unmarshal(body, &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
@@ -0,0 +1,91 @@
+package dictionary
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type item struct {
+	ID int `json:"id"`
+}
+
+func TestFetchPage(t *testing.T) {
+	initialBackoff = time.Millisecond
+	defer func() { initialBackoff = time.Second }()
+
+	tests := []struct {
+		name     string
+		statuses []int // returned by the attempts in order, the last one repeats
+		body     string
+		attempts int
+		err      bool
+	}{
+		{name: "ok", statuses: []int{http.StatusOK}, attempts: 1},
+		{name: "retried server error", statuses: []int{http.StatusBadGateway, http.StatusTooManyRequests, http.StatusOK}, attempts: 3},
+		{name: "not found", statuses: []int{http.StatusNotFound}, attempts: 1, err: true},
+		{name: "attempts exhausted", statuses: []int{http.StatusServiceUnavailable}, attempts: maxAttempts, err: true},
+		{name: "malformed body", statuses: []int{http.StatusOK}, body: `{"data": [`, attempts: 1, err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempts := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "key", r.Header.Get("Authorization"))
+				status := tt.statuses[min(attempts, len(tt.statuses)-1)]
+				attempts++
+				w.WriteHeader(status)
+				body := tt.body
+				if body == "" {
+					body = `{"data": [{"id": 1}, {"id": 2}], "pagination": {"current_page": 1, "has_more": true}}`
+				}
+				_, _ = w.Write([]byte(body))
+			}))
+			defer server.Close()
+
+			page, err := FetchPage[item](context.Background(), "key", server.URL)
+			assert.Equal(t, tt.attempts, attempts)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, []item{{ID: 1}, {ID: 2}}, page.Data)
+			assert.True(t, page.Pagination.HasMore)
+		})
+	}
+}
+
+func TestRetryable(t *testing.T) {
+	tests := []struct {
+		name      string
+		err       error
+		retryable bool
+	}{
+		{name: "rate limited", err: &StatusError{Code: http.StatusTooManyRequests}, retryable: true},
+		{name: "server error", err: &StatusError{Code: http.StatusBadGateway}, retryable: true},
+		{name: "client error", err: &StatusError{Code: http.StatusUnauthorized}},
+		{name: "connection refused", err: &url.Error{Op: "Get", URL: "https://api", Err: &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}}, retryable: true},
+		{name: "truncated body", err: io.ErrUnexpectedEOF, retryable: true},
+		{name: "unsupported scheme", err: &url.Error{Op: "Get", URL: "ftp://api", Err: errors.New("unsupported protocol scheme")}},
+		{name: "canceled", err: &url.Error{Op: "Get", URL: "https://api", Err: context.Canceled}},
+		{name: "deadline", err: context.DeadlineExceeded},
+		{name: "malformed json", err: &json.SyntaxError{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.retryable, retryable(tt.err))
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package matchguru
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	fbauth "firebase.google.com/go/v4/auth"
+	"github.com/klipach/matchguru/chat"
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/feedback"
+	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/sport"
+	"github.com/klipach/matchguru/usage"
+)
+
+// submitFeedback stores the user's rating of a bot answer together with the answer,
+// the question, the model, the prompt version and the game the chat is about.
+// The game is stored by ID, cmd/feedbackexport resolves it, so a rating costs no fixture API call.
+func (b *bot) submitFeedback(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	ctx := r.Context()
+	logger := log.LoggerFromContext(ctx)
+
+	var req contract.FeedbackRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	if err := feedback.Validate(req); err != nil {
+		logger.Warn("invalid feedback", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	// validated above
+	sp, _ := sport.Parse(req.Sport)
+
+	question, answer, err := chat.Answer(ctx, token.UID, req.ChatID, req.MessageIndex)
+	if err != nil {
+		chatError(w, r, "error while loading rated message", err)
+		return
+	}
+
+	f := feedback.Feedback{
+		UserID:       token.UID,
+		ChatID:       req.ChatID,
+		MessageIndex: req.MessageIndex,
+		Rating:       req.Rating,
+		Category:     req.Category,
+		Comment:      req.Comment,
+		Question:     question,
+		Answer:       answer,
+		GameID:       req.GameID,
+		Sport:        string(sp),
+		CreatedAt:    time.Now().UTC(),
+	}
+	// the model and the prompt version the answer was generated with, a plan change or a prompt rollout since doesn't matter
+	entry, ok, err := usage.ForAnswer(ctx, token.UID, req.ChatID, req.MessageIndex)
+	switch {
+	case err != nil:
+		logger.Error("error while loading answer usage", slog.String(ErrorMsgLogField, err.Error()))
+	case !ok:
+		logger.Warn("no usage recorded for rated answer", slog.Int(chatIDLogField, req.ChatID), slog.Int("messageIndex", req.MessageIndex))
+	default:
+		f.Model = entry.Model
+		f.PromptVersion = entry.PromptVersion
+	}
+
+	if err := feedback.Save(ctx, f); err != nil {
+		logger.Error("error while saving feedback", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	logger.Info("feedback saved",
+		slog.Int(chatIDLogField, req.ChatID),
+		slog.String("rating", req.Rating),
+		slog.String("category", req.Category),
+	)
+	w.WriteHeader(http.StatusNoContent)
+}
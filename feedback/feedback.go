@@ -0,0 +1,87 @@
+package feedback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/sport"
+	"github.com/klipach/matchguru/store"
+)
+
+const (
+	firestoreUserCollection = "users"
+	// Collection is the per user feedback subcollection, exported across users with a collection group query
+	Collection = "feedback"
+
+	RatingUp   = "up"
+	RatingDown = "down"
+
+	maxCommentLength = 2000
+)
+
+var categories = []string{"wrong_info", "outdated", "formatting", "off_topic", "other"}
+
+// Feedback is a user rating of a bot answer, stored with the context the answer was generated in.
+type Feedback struct {
+	UserID        string    `firestore:"user_id" json:"user_id"`
+	ChatID        int       `firestore:"chat_id" json:"chat_id"`
+	MessageIndex  int       `firestore:"message_index" json:"message_index"`
+	Rating        string    `firestore:"rating" json:"rating"`
+	Category      string    `firestore:"category" json:"category"`
+	Comment       string    `firestore:"comment" json:"comment"`
+	Question      string    `firestore:"question" json:"question"`
+	Answer        string    `firestore:"answer" json:"answer"`
+	Model         string    `firestore:"model" json:"model"`
+	PromptVersion string    `firestore:"prompt_version" json:"prompt_version"`
+	GameID        int       `firestore:"game_id" json:"game_id"`
+	Sport         string    `firestore:"sport" json:"sport"`
+	Fixture       string    `firestore:"fixture,omitempty" json:"fixture"` // resolved from GameID by cmd/feedbackexport
+	CreatedAt     time.Time `firestore:"created_at" json:"created_at"`
+}
+
+// Validate checks the feedback request, a thumbs down requires a category.
+func Validate(req contract.FeedbackRequest) error {
+	// client-created chats start at ID 0, so only the index is checked
+	if req.MessageIndex < 0 {
+		return errors.New("invalid message_index")
+	}
+	if req.Rating != RatingUp && req.Rating != RatingDown {
+		return fmt.Errorf("invalid rating %q", req.Rating)
+	}
+	if req.Rating == RatingDown && req.Category == "" {
+		return errors.New("category is required for thumbs down")
+	}
+	if req.Category != "" && !slices.Contains(categories, req.Category) {
+		return fmt.Errorf("invalid category %q", req.Category)
+	}
+	if utf8.RuneCountInString(req.Comment) > maxCommentLength {
+		return errors.New("comment is too long")
+	}
+	if _, err := sport.Parse(req.Sport); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Save stores the feedback in users/{userID}/feedback, one document per message,
+// so a changed rating replaces the previous one.
+func Save(ctx context.Context, f Feedback) error {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = client.Collection(firestoreUserCollection).Doc(f.UserID).
+		Collection(Collection).Doc(docID(f.ChatID, f.MessageIndex)).
+		Set(ctx, f)
+	return err
+}
+
+func docID(chatID, messageIndex int) string {
+	return strconv.Itoa(chatID) + "_" + strconv.Itoa(messageIndex)
+}
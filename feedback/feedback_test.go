@@ -0,0 +1,39 @@
+package feedback
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/klipach/matchguru/contract"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		req   contract.FeedbackRequest
+		valid bool
+	}{
+		{name: "thumbs up", req: contract.FeedbackRequest{ChatID: 1, MessageIndex: 1, Rating: RatingUp}, valid: true},
+		{name: "thumbs down with category", req: contract.FeedbackRequest{ChatID: 1, MessageIndex: 1, Rating: RatingDown, Category: "outdated", Comment: "old table"}, valid: true},
+		{name: "thumbs down without category", req: contract.FeedbackRequest{ChatID: 1, MessageIndex: 1, Rating: RatingDown}, valid: false},
+		{name: "unknown category", req: contract.FeedbackRequest{ChatID: 1, MessageIndex: 1, Rating: RatingDown, Category: "boring"}, valid: false},
+		{name: "unknown rating", req: contract.FeedbackRequest{ChatID: 1, MessageIndex: 1, Rating: "meh"}, valid: false},
+		{name: "chat 0", req: contract.FeedbackRequest{ChatID: 0, MessageIndex: 1, Rating: RatingUp}, valid: true},
+		{name: "negative index", req: contract.FeedbackRequest{ChatID: 1, MessageIndex: -1, Rating: RatingUp}, valid: false},
+		{name: "cricket", req: contract.FeedbackRequest{ChatID: 1, MessageIndex: 1, Rating: RatingUp, Sport: "cricket"}, valid: true},
+		{name: "unknown sport", req: contract.FeedbackRequest{ChatID: 1, MessageIndex: 1, Rating: RatingUp, Sport: "curling"}, valid: false},
+		{name: "comment too long", req: contract.FeedbackRequest{ChatID: 1, MessageIndex: 1, Rating: RatingUp, Comment: strings.Repeat("a", maxCommentLength+1)}, valid: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.req)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.Error(t, err)
+			}
+		})
+	}
+}
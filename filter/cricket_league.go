@@ -0,0 +1,5 @@
+package filter
+
+// generated by cmd/league -sport cricket from https://cricket.sportmonks.com/api/v2.0/leagues
+// league name should be in lowercase
+var cricketLeagueNameToID = map[string]int{}
@@ -0,0 +1,5 @@
+package filter
+
+// generated by cmd/team -sport cricket from https://cricket.sportmonks.com/api/v2.0/teams?include=country
+// team name should be in lowercase
+var cricketTeamNameToID = map[string]int{}
@@ -0,0 +1,75 @@
+package filter
+
+import (
+	"strconv"
+
+	"github.com/klipach/matchguru/sport"
+)
+
+// Dictionary resolves the lowercase English names of the internal links of one sport.
+type Dictionary interface {
+	// League returns the league ID, ok is true for a known league even without mapping yet (ID 0).
+	League(name string) (id int, ok bool)
+	Team(name string) (id int, ok bool)
+	// HasLeague and HasTeam report whether some name maps to the ID
+	HasLeague(id int) bool
+	HasTeam(id int) bool
+	// Path is the app route of the entity, such as "leagues/8".
+	Path(entity string, id int) string
+}
+
+// mapDictionary is a Dictionary of map literals generated by cmd/league and cmd/team.
+type mapDictionary struct {
+	leagues map[string]int
+	teams   map[string]int
+	// prefix of the app routes, football routes predate other sports and have none
+	prefix string
+}
+
+func (d mapDictionary) League(name string) (int, bool) {
+	id, ok := d.leagues[name]
+	return id, ok
+}
+
+func (d mapDictionary) Team(name string) (int, bool) {
+	id, ok := d.teams[name]
+	return id, ok
+}
+
+func (d mapDictionary) HasLeague(id int) bool {
+	return hasID(d.leagues, id)
+}
+
+func (d mapDictionary) HasTeam(id int) bool {
+	return hasID(d.teams, id)
+}
+
+// hasID scans the map, it is called by user requests such as following a team, not per streamed chunk.
+func hasID(names map[string]int, id int) bool {
+	if id == 0 {
+		return false
+	}
+	for _, v := range names {
+		if v == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (d mapDictionary) Path(entity string, id int) string {
+	return d.prefix + entity + "s/" + strconv.Itoa(id)
+}
+
+var dictionaries = map[sport.Sport]Dictionary{
+	sport.Football: mapDictionary{leagues: leagueNameToID, teams: teamNameToID},
+	sport.Cricket:  mapDictionary{leagues: cricketLeagueNameToID, teams: cricketTeamNameToID, prefix: "cricket/"},
+}
+
+// DictionaryFor returns the dictionary of the sport, the default sport's for an unknown one.
+func DictionaryFor(s sport.Sport) Dictionary {
+	if d, ok := dictionaries[s]; ok {
+		return d
+	}
+	return dictionaries[sport.Default]
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"regexp"
 	"strings"
+
+	"github.com/klipach/matchguru/telemetry"
 )
 
 var (
@@ -15,18 +17,18 @@ type ExternalLinkFilter struct {
 	buffering bool
 }
 
-func (ef *ExternalLinkFilter) ProcessChunk(_ context.Context, chunk string) string {
+func (ef *ExternalLinkFilter) ProcessChunk(ctx context.Context, chunk string) string {
 	if chunk == "" { // empty chunk - end of stream
 		ef.buffering = false
 		ret := ef.buffer
 		ef.buffer = ""
-		return externalLinkRegex.ReplaceAllString(ret, "")
+		return stripExternalLinks(ctx, ret)
 	}
 	if externalLinkRegex.MatchString(chunk) { // if chunk is a link, remove it and return the chunk
 		ef.buffering = false
 		ret := ef.buffer + chunk
 		ef.buffer = ""
-		return externalLinkRegex.ReplaceAllString(ret, "")
+		return stripExternalLinks(ctx, ret)
 	}
 	if strings.Contains(chunk, "[") {
 		if ef.buffering { // if we are in buffering state and see second [, flush buffer and start to buffer again
@@ -46,7 +48,7 @@ func (ef *ExternalLinkFilter) ProcessChunk(_ context.Context, chunk string) stri
 	}
 	if strings.Contains(chunk, ")") && ef.buffering { // potential link, trying to remove
 		ret := ef.buffer + chunk
-		ret = externalLinkRegex.ReplaceAllString(ret, "")
+		ret = stripExternalLinks(ctx, ret)
 		ef.buffering = false
 		ef.buffer = ""
 		return ret
@@ -57,3 +59,8 @@ func (ef *ExternalLinkFilter) ProcessChunk(_ context.Context, chunk string) stri
 	}
 	return chunk
 }
+
+func stripExternalLinks(ctx context.Context, text string) string {
+	telemetry.RecordExternalLinksStripped(ctx, len(externalLinkRegex.FindAllStringIndex(text, -1)))
+	return externalLinkRegex.ReplaceAllString(text, "")
+}
@@ -4,19 +4,49 @@ import (
 	"context"
 	"log/slog"
 	"regexp"
-	"strconv"
 	"strings"
 
+	"github.com/klipach/matchguru/locale"
 	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/sport"
+	"github.com/klipach/matchguru/telemetry"
+	"golang.org/x/text/language"
 )
 
 var (
 	internalLinkRegex = regexp.MustCompile(`\{([^}]+)\}`)
 )
 
+// entity types of internal links, a missing mapping doesn't tell a team from a league
+const (
+	entityLeague  = "league"
+	entityTeam    = "team"
+	entityUnknown = "unknown"
+)
+
 type InternalLinkFilter struct {
 	buffer    string
 	buffering bool
+	// dictionary resolves the names, the default sport's when nil
+	dictionary Dictionary
+	// language the answer is written in, display names in another script are replaced with the English names
+	language language.Tag
+	// unresolved are the English names without a link mapping, in lowercase
+	unresolved []string
+}
+
+// NewInternalLinkFilter returns a filter linking the names found in the dictionary,
+// in answers written in the language, the default language when undetermined.
+func NewInternalLinkFilter(d Dictionary, lang language.Tag) *InternalLinkFilter {
+	if lang == language.Und {
+		lang = locale.Default
+	}
+	return &InternalLinkFilter{dictionary: d, language: lang}
+}
+
+// Unresolved returns the names of the links processed so far which have no mapping.
+func (ilf *InternalLinkFilter) Unresolved() []string {
+	return ilf.unresolved
 }
 
 func (ilf *InternalLinkFilter) ProcessChunk(ctx context.Context, chunk string) string {
@@ -30,7 +60,7 @@ func (ilf *InternalLinkFilter) ProcessChunk(ctx context.Context, chunk string) s
 		ilf.buffering = false
 		ret := ilf.buffer + chunk
 		ilf.buffer = ""
-		return convertInternalLinks(ctx, ret)
+		return ilf.convertInternalLinks(ctx, ret)
 	}
 	if strings.Contains(chunk, "{") {
 		if ilf.buffering { // if we are in buffering state and see second {, flush buffer and start to buffer again
@@ -44,7 +74,7 @@ func (ilf *InternalLinkFilter) ProcessChunk(ctx context.Context, chunk string) s
 	}
 	if strings.Contains(chunk, "}") && ilf.buffering { // potential internal link, trying to process
 		ret := ilf.buffer + chunk
-		ret = convertInternalLinks(ctx, ret)
+		ret = ilf.convertInternalLinks(ctx, ret)
 		ilf.buffering = false
 		ilf.buffer = ""
 		return ret
@@ -57,7 +87,7 @@ func (ilf *InternalLinkFilter) ProcessChunk(ctx context.Context, chunk string) s
 }
 
 // convertInternalLinks converts text wrapped in curly braces to internal links
-func convertInternalLinks(ctx context.Context, text string) string {
+func (ilf *InternalLinkFilter) convertInternalLinks(ctx context.Context, text string) string {
 	return internalLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
 		logger := log.LoggerFromContext(ctx)
 		// extract the text between curly braces
@@ -71,19 +101,46 @@ func convertInternalLinks(ctx context.Context, text string) string {
 
 		linkTitle := parts[0]
 		linkTitleInEnglish := parts[1]
+		name := strings.ToLower(strings.TrimSpace(linkTitleInEnglish))
+		if strings.TrimSpace(linkTitle) == "" || !writtenIn(linkTitle, ilf.language) {
+			logger.Info("display name not in the answer language", slog.String("match", match), slog.String("language", ilf.language.String()))
+			linkTitle = strings.TrimSpace(linkTitleInEnglish)
+		}
 
-		if leagueID, ok := leagueNameToID[strings.ToLower(linkTitleInEnglish)]; ok {
+		dictionary := ilf.dictionary
+		if dictionary == nil {
+			dictionary = DictionaryFor(sport.Default)
+		}
+		if leagueID, ok := dictionary.League(name); ok {
 			if leagueID == 0 { // league found but no mapping yet
+				telemetry.RecordInternalLink(ctx, entityLeague, false)
+				ilf.unresolved = append(ilf.unresolved, name)
 				return linkTitle
 			}
-			return "[" + linkTitle + "](leagues/" + strconv.Itoa(leagueID) + ")"
+			telemetry.RecordInternalLink(ctx, entityLeague, true)
+			return "[" + linkTitle + "](" + dictionary.Path(entityLeague, leagueID) + ")"
 		}
-		if teamID, ok := teamNameToID[strings.ToLower(linkTitleInEnglish)]; ok {
-			return "[" + linkTitle + "](teams/" + strconv.Itoa(teamID) + ")"
+		if teamID, ok := dictionary.Team(name); ok {
+			telemetry.RecordInternalLink(ctx, entityTeam, true)
+			return "[" + linkTitle + "](" + dictionary.Path(entityTeam, teamID) + ")"
 		}
 
 		// if link mapping not found, just return the content without braces
-		logger.Info("link mapping not found", slog.String("linkTitle", strings.ToLower(linkTitleInEnglish)))
+		telemetry.RecordInternalLink(ctx, entityUnknown, false)
+		ilf.unresolved = append(ilf.unresolved, name)
+		logger.Info("link mapping not found", slog.String("linkTitle", name))
 		return linkTitle
 	})
 }
+
+var markdownLinkRegex = regexp.MustCompile(`\[([^\]]*)\]\([^)]*\)`)
+
+// PlainText replaces the internal links with their titles and the markdown links with their text,
+// for answers shown outside of the app, such as notifications.
+func PlainText(text string) string {
+	text = internalLinkRegex.ReplaceAllStringFunc(text, func(match string) string {
+		title, _, _ := strings.Cut(match[1:len(match)-1], "|")
+		return title
+	})
+	return markdownLinkRegex.ReplaceAllString(text, "$1")
+}
@@ -0,0 +1,113 @@
+package filter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/klipach/matchguru/sport"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/text/language"
+)
+
+func TestInternalLinkFilterDictionary(t *testing.T) {
+	cricket := mapDictionary{
+		leagues: map[string]int{"big bash league": 5, "the hundred": 0},
+		teams:   map[string]int{"perth scorchers": 12},
+		prefix:  "cricket/",
+	}
+	tests := []struct {
+		name               string
+		dictionary         Dictionary
+		language           language.Tag
+		chunk              string
+		expected           string
+		expectedUnresolved []string
+	}{
+		{
+			name:     "football by default",
+			chunk:    "{Premier League|Premier League}",
+			expected: "[Premier League](leagues/8)",
+		},
+		{
+			name:     "localized display name",
+			language: language.Ukrainian,
+			chunk:    "{Прем'єр-ліга|Premier League}",
+			expected: "[Прем'єр-ліга](leagues/8)",
+		},
+		{
+			name:     "display name in English for another language",
+			language: language.Ukrainian,
+			chunk:    "{Premier League|Premier League}",
+			expected: "[Premier League](leagues/8)",
+		},
+		{
+			name:     "display name in another script than the language",
+			language: language.English,
+			chunk:    "{Прем'єр-ліга|Premier League}",
+			expected: "[Premier League](leagues/8)",
+		},
+		{
+			name:     "empty display name",
+			chunk:    "{ |Premier League}",
+			expected: "[Premier League](leagues/8)",
+		},
+		{
+			name:       "cricket league",
+			dictionary: cricket,
+			chunk:      "{Big Bash League|Big Bash League}",
+			expected:   "[Big Bash League](cricket/leagues/5)",
+		},
+		{
+			name:       "cricket team",
+			dictionary: cricket,
+			chunk:      "{Perth Scorchers|Perth Scorchers}",
+			expected:   "[Perth Scorchers](cricket/teams/12)",
+		},
+		{
+			name:               "league without mapping",
+			dictionary:         cricket,
+			chunk:              "{The Hundred|The Hundred}",
+			expected:           "The Hundred",
+			expectedUnresolved: []string{"the hundred"},
+		},
+		{
+			name:               "football names are not cricket names",
+			dictionary:         cricket,
+			chunk:              "{Premier League|Premier League}",
+			expected:           "Premier League",
+			expectedUnresolved: []string{"premier league"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ilf := NewInternalLinkFilter(tt.dictionary, tt.language)
+			assert.Equal(t, tt.expected, ilf.ProcessChunk(context.Background(), tt.chunk))
+			assert.Equal(t, tt.expectedUnresolved, ilf.Unresolved())
+		})
+	}
+}
+
+func TestDictionaryHasID(t *testing.T) {
+	football := DictionaryFor(sport.Football)
+	assert.True(t, football.HasLeague(8))
+	assert.False(t, football.HasLeague(0), "placeholders without mapping are not leagues")
+	assert.False(t, football.HasTeam(-1))
+	assert.False(t, DictionaryFor(sport.Cricket).HasLeague(8))
+}
+
+func TestPlainText(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		expected string
+	}{
+		{name: "internal link", text: "{Arsenal FC|Arsenal FC} host {Chelsea|Chelsea}.", expected: "Arsenal FC host Chelsea."},
+		{name: "markdown link", text: "See [the table](https://example.com/table).", expected: "See the table."},
+		{name: "plain", text: "Kick-off at 20:00.", expected: "Kick-off at 20:00."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, PlainText(tt.text))
+		})
+	}
+}
@@ -0,0 +1,27 @@
+package filter
+
+import (
+	"strings"
+
+	"github.com/klipach/matchguru/locale"
+)
+
+// maxRefusalLength keeps answers which refuse only the off-topic part of a mixed question from counting as refusals.
+const maxRefusalLength = 400
+
+// refusalMarkers are phrases of the polite refusal templates of all sports and languages, in lowercase.
+var refusalMarkers = locale.AllRefusalMarkers()
+
+// IsRefusal reports whether the answer is the off-topic refusal asked for by the main prompt.
+func IsRefusal(answer string) bool {
+	if len([]rune(answer)) > maxRefusalLength {
+		return false
+	}
+	answer = strings.ToLower(answer)
+	for _, marker := range refusalMarkers {
+		if strings.Contains(answer, marker) {
+			return true
+		}
+	}
+	return false
+}
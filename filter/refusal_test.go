@@ -0,0 +1,29 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsRefusal(t *testing.T) {
+	tests := []struct {
+		name     string
+		answer   string
+		expected bool
+	}{
+		{"template", "I appreciate your question, but I'm specialized exclusively in soccer/football. I'd be happy to help you with any soccer-related queries instead!", true},
+		{"other template", "That's outside my area of expertise. As a dedicated soccer analyst, I focus only on football matters.", true},
+		{"cricket template", "I'm designed to be your cricket expert only. Let me help you with cricket predictions, player analysis, or match insights instead!", true},
+		{"localized template", "Дякую за запитання, але моя спеціалізація — виключно футбол. Із задоволенням допоможу з будь-якими запитаннями про футбол!", true},
+		{"case insensitive", "I'M DESIGNED TO BE YOUR SOCCER EXPERT ONLY.", true},
+		{"answer", "Arsenal won 2-1 against Chelsea.", false},
+		{"long answer with refusal part", "Betting on elections is outside my area of expertise. " + strings.Repeat("Arsenal looks strong. ", 30), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, IsRefusal(tt.answer))
+		})
+	}
+}
@@ -0,0 +1,43 @@
+package filter
+
+import (
+	"unicode"
+
+	"golang.org/x/text/language"
+)
+
+// scripts are the Unicode scripts names are written in per ISO 15924 code of a language,
+// Latin is allowed in every language as club and league names are often kept as is.
+var scripts = map[string][]*unicode.RangeTable{
+	"Latn": {},
+	"Cyrl": {unicode.Cyrillic},
+	"Grek": {unicode.Greek},
+	"Arab": {unicode.Arabic},
+	"Hebr": {unicode.Hebrew},
+	"Deva": {unicode.Devanagari},
+	"Thai": {unicode.Thai},
+	"Hans": {unicode.Han},
+	"Hant": {unicode.Han},
+	"Jpan": {unicode.Han, unicode.Hiragana, unicode.Katakana},
+	"Kore": {unicode.Hangul, unicode.Han},
+}
+
+// writtenIn reports whether the letters of the name are in the script of the language or in Latin.
+// Languages of other scripts are not validated. Latin languages can't be told apart, so a name
+// in English passes for Spanish.
+func writtenIn(name string, lang language.Tag) bool {
+	script, _ := lang.Script()
+	tables, ok := scripts[script.String()]
+	if !ok {
+		return true
+	}
+	for _, r := range name {
+		if !unicode.IsLetter(r) || unicode.Is(unicode.Latin, r) {
+			continue
+		}
+		if !unicode.In(r, tables...) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,111 @@
+package fixture
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/klipach/matchguru/telemetry"
+)
+
+type CricketFixtureAPIResponse struct {
+	Data struct {
+		ID         int       `json:"id"`
+		Round      string    `json:"round"`
+		Type       string    `json:"type"`
+		StartingAt time.Time `json:"starting_at"`
+		League     struct {
+			Name string `json:"name"`
+		} `json:"league"`
+		Season struct {
+			Name string `json:"name"`
+		} `json:"season"`
+		Venue struct {
+			Name string `json:"name"`
+			City string `json:"city"`
+		} `json:"venue"`
+		LocalTeam struct {
+			Name string `json:"name"`
+		} `json:"localteam"`
+		VisitorTeam struct {
+			Name string `json:"name"`
+		} `json:"visitorteam"`
+	} `json:"data"`
+}
+
+// CricketClient fetches fixtures from the SportMonks cricket API,
+// which is a separate v2 API authenticated with the api_token query parameter.
+type CricketClient struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
+
+func NewCricketClient(apiKey, baseURL string) *CricketClient {
+	return &CricketClient{
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Transport: telemetry.Transport(http.DefaultTransport)},
+	}
+}
+
+func (c *CricketClient) Fetch(ctx context.Context, fixtureID int) (*Fixture, error) {
+	query := url.Values{
+		"api_token": {c.apiKey},
+		"include":   {"localteam,visitorteam,league,season,venue"},
+	}
+	req, err := http.NewRequestWithContext(
+		ctx,
+		http.MethodGet,
+		c.baseURL+fmt.Sprintf("/api/v2.0/fixtures/%d?", fixtureID)+query.Encode(),
+		http.NoBody,
+	)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Add(contentTypeHeader, "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, telemetry.RedactError(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var fixture CricketFixtureAPIResponse
+	if err := json.Unmarshal(body, &fixture); err != nil {
+		return nil, err
+	}
+
+	data := fixture.Data
+	name := data.LocalTeam.Name + " vs " + data.VisitorTeam.Name
+	if data.Round != "" {
+		name += ", " + data.Round
+	}
+	return &Fixture{
+		ID:         data.ID,
+		Name:       name,
+		StartingAt: data.StartingAt,
+		League:     League{Name: data.League.Name},
+		Venue: Venue{
+			Name: data.Venue.Name,
+			City: data.Venue.City,
+		},
+		HomeTeam: Team{Name: data.LocalTeam.Name},
+		AwayTeam: Team{Name: data.VisitorTeam.Name},
+		Season:   data.Season.Name,
+		Format:   data.Type,
+	}, nil
+}
@@ -0,0 +1,65 @@
+package fixture
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCricketClientFetch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v2.0/fixtures/42", r.URL.Path)
+		assert.Equal(t, "token", r.URL.Query().Get("api_token"))
+		_, _ = w.Write([]byte(`{"data": {
+			"id": 42,
+			"round": "1st T20I",
+			"type": "T20I",
+			"starting_at": "2025-09-19T14:00:00.000000Z",
+			"league": {"name": "Twenty20 International"},
+			"season": {"name": "2025"},
+			"venue": {"name": "Lord's", "city": "London"},
+			"localteam": {"name": "England"},
+			"visitorteam": {"name": "Australia"}
+		}}`))
+	}))
+	defer server.Close()
+
+	f, err := NewCricketClient("token", server.URL).Fetch(context.Background(), 42)
+	require.NoError(t, err)
+	assert.Equal(t, &Fixture{
+		ID:         42,
+		Name:       "England vs Australia, 1st T20I",
+		StartingAt: time.Date(2025, 9, 19, 14, 0, 0, 0, time.UTC),
+		League:     League{Name: "Twenty20 International"},
+		Venue:      Venue{Name: "Lord's", City: "London"},
+		Season:     "2025",
+		HomeTeam:   Team{Name: "England"},
+		AwayTeam:   Team{Name: "Australia"},
+		Format:     "T20I",
+	}, f)
+}
+
+func TestCricketClientFetchStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	_, err := NewCricketClient("token", server.URL).Fetch(context.Background(), 42)
+	assert.ErrorContains(t, err, "unexpected status code: 404")
+}
+
+func TestCricketClientFetchErrorRedactsKey(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	server.Close()
+
+	_, err := NewCricketClient("secret-key", server.URL).Fetch(context.Background(), 42)
+	require.Error(t, err)
+	assert.NotContains(t, err.Error(), "secret-key")
+	assert.Contains(t, err.Error(), "api_token=REDACTED")
+}
@@ -6,11 +6,13 @@ import (
 	"fmt"
 	"io"
 	"net/http"
-	"os"
 	"time"
+
+	"github.com/klipach/matchguru/telemetry"
 )
 
 type League struct {
+	ID      int
 	Name    string
 	Country string
 }
@@ -22,6 +24,7 @@ type Venue struct {
 }
 
 type Team struct {
+	ID      int
 	Name    string
 	Country string
 }
@@ -35,112 +38,153 @@ type Fixture struct {
 	Season     string
 	HomeTeam   Team
 	AwayTeam   Team
+	Format     string // cricket match format such as T20, ODI or Test
+	State      string // SportMonks state developer name such as NS, INPLAY_1ST_HALF or FT
+	// Live is the score and events of a game in play, fetched separately
+	Live *Live
+	// Odds are the pre-match prices of a game not started yet, fetched separately
+	Odds *Odds
+}
+
+// InPlay reports whether the game is being played, breaks such as half-time included.
+func (f *Fixture) InPlay() bool {
+	return inPlayStates[f.State]
+}
+
+// Fetcher fetches a fixture from the API of one sport.
+type Fetcher interface {
+	Fetch(ctx context.Context, fixtureID int) (*Fixture, error)
 }
 
 type FixtureAPIResponse struct {
-	Data struct {
-		ID                  int    `json:"id"`
-		Name                string `json:"name"`
-		StartingAtTimestamp int64  `json:"starting_at_timestamp"`
-		League              struct {
-			Name    string `json:"name"`
-			Country struct {
-				Name string `json:"name"`
-			} `json:"country"`
-		} `json:"league"`
-		Season struct {
+	Data FixtureData `json:"data"`
+}
+
+type FixtureData struct {
+	ID                  int    `json:"id"`
+	Name                string `json:"name"`
+	StartingAtTimestamp int64  `json:"starting_at_timestamp"`
+	LeagueID            int    `json:"league_id"`
+	League              struct {
+		Name    string `json:"name"`
+		Country struct {
+			Name string `json:"name"`
+		} `json:"country"`
+	} `json:"league"`
+	Season struct {
+		Name string `json:"name"`
+	} `json:"season"`
+	Venue struct {
+		Name    string `json:"name"`
+		City    string `json:"city_name"`
+		Country struct {
+			Name string `json:"name"`
+		} `json:"country"`
+	} `json:"venue"`
+	Participants []struct {
+		ID   int `json:"id"`
+		Meta struct {
+			Location string `json:"location"`
+		} `json:"meta"`
+		Name    string `json:"name"`
+		Country struct {
 			Name string `json:"name"`
-		} `json:"season"`
-		Venue struct {
-			Name    string `json:"name"`
-			City    string `json:"city_name"`
-			Country struct {
-				Name string `json:"name"`
-			} `json:"country"`
-		} `json:"venue"`
-		Participants []struct {
-			Meta struct {
-				Location string `json:"location"`
-			} `json:"meta"`
-			Name    string `json:"name"`
-			Country struct {
-				Name string `json:"name"`
-			} `json:"country"`
-		} `json:"participants"`
-	} `json:"data"`
+		} `json:"country"`
+	} `json:"participants"`
+	State struct {
+		DeveloperName string `json:"developer_name"`
+	} `json:"state"`
 }
 
 var (
-	sportmonksApiKey    = os.Getenv("SPORTMONKS_API_KEY")
-	sportmonksBaseURL   = "https://api.sportmonks.com"
 	authorizationHeader = "Authorization"
 	contentTypeHeader   = "Content-Type"
 )
 
-func Fetch(ctx context.Context, fixtureID int) (*Fixture, error) {
-	req, err := http.NewRequestWithContext(
-		ctx,
-		http.MethodGet,
-		sportmonksBaseURL+fmt.Sprintf("/v3/football/fixtures/%d/?include=league:name;season:name;round:name;league.country;participants.country:name;scores;venue;venue.country;lineups.player;referees.referee;state", fixtureID),
-		http.NoBody,
-	)
-	if err != nil {
-		return nil, err
-	}
-
-	req.Header.Add(authorizationHeader, sportmonksApiKey)
-	req.Header.Add(contentTypeHeader, "application/json")
-
-	resp, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
+// Client fetches fixtures from the SportMonks football API.
+type Client struct {
+	apiKey     string
+	baseURL    string
+	httpClient *http.Client
+}
 
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
+func NewClient(apiKey, baseURL string) *Client {
+	return &Client{
+		apiKey:     apiKey,
+		baseURL:    baseURL,
+		httpClient: &http.Client{Transport: telemetry.Transport(http.DefaultTransport)},
 	}
+}
 
+func (c *Client) Fetch(ctx context.Context, fixtureID int) (*Fixture, error) {
 	var fixture FixtureAPIResponse
-	if err := json.Unmarshal(body, &fixture); err != nil {
+	path := fmt.Sprintf("/v3/football/fixtures/%d/?include=league:name;season:name;round:name;league.country;participants.country:name;scores;venue;venue.country;lineups.player;referees.referee;state", fixtureID)
+	if err := c.get(ctx, path, &fixture); err != nil {
 		return nil, err
 	}
 
+	return toFixture(fixture.Data), nil
+}
+
+func toFixture(data FixtureData) *Fixture {
 	var homeTeam, awayTeam Team
-	for _, participant := range fixture.Data.Participants {
+	for _, participant := range data.Participants {
+		team := Team{
+			ID:      participant.ID,
+			Name:    participant.Name,
+			Country: participant.Country.Name,
+		}
 		if participant.Meta.Location == "home" {
-			homeTeam = Team{
-				Name:    participant.Name,
-				Country: participant.Country.Name,
-			}
+			homeTeam = team
 		} else {
-			awayTeam = Team{
-				Name:    participant.Name,
-				Country: participant.Country.Name,
-			}
+			awayTeam = team
 		}
 	}
 
 	return &Fixture{
-		ID:         fixture.Data.ID,
-		Name:       fixture.Data.Name,
-		StartingAt: time.Unix(fixture.Data.StartingAtTimestamp, 0),
+		ID:         data.ID,
+		Name:       data.Name,
+		StartingAt: time.Unix(data.StartingAtTimestamp, 0),
 		League: League{
-			Name:    fixture.Data.League.Name,
-			Country: fixture.Data.League.Country.Name,
+			ID:      data.LeagueID,
+			Name:    data.League.Name,
+			Country: data.League.Country.Name,
 		},
 		Venue: Venue{
-			Name:    fixture.Data.Venue.Name,
-			City:    fixture.Data.Venue.City,
-			Country: fixture.Data.Venue.Country.Name,
+			Name:    data.Venue.Name,
+			City:    data.Venue.City,
+			Country: data.Venue.Country.Name,
 		},
 		HomeTeam: homeTeam,
 		AwayTeam: awayTeam,
-		Season:   fixture.Data.Season.Name,
-	}, nil
+		Season:   data.Season.Name,
+		State:    data.State.DeveloperName,
+	}
+}
+
+// get decodes the JSON response of the API path into v.
+func (c *Client) get(ctx context.Context, path string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, http.NoBody)
+	if err != nil {
+		return err
+	}
+
+	req.Header.Add(authorizationHeader, c.apiKey)
+	req.Header.Add(contentTypeHeader, "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(body, v)
 }
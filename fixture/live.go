@@ -0,0 +1,160 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+)
+
+// inPlayStates are the SportMonks state developer names of a game being played
+var inPlayStates = map[string]bool{
+	"INPLAY_1ST_HALF":  true,
+	"HT":               true,
+	"INPLAY_2ND_HALF":  true,
+	"BREAK":            true,
+	"INPLAY_ET":        true,
+	"EXTRA_TIME_BREAK": true,
+	"INPLAY_PENALTIES": true,
+	"PEN_BREAK":        true,
+}
+
+// currentScore is the description of the running score among the scores of a fixture
+const currentScore = "CURRENT"
+
+// Live is the state of a game in play.
+type Live struct {
+	State     string
+	HomeScore int
+	AwayScore int
+	Events    []Event // in the order they happened
+}
+
+// InPlay reports whether the game is still being played.
+func (l *Live) InPlay() bool {
+	return inPlayStates[l.State]
+}
+
+// Event is a goal, card, substitution or another in-play event.
+type Event struct {
+	Minute        int
+	ExtraMinute   int // added time, such as 3 for 45+3
+	Type          string
+	Team          string
+	Player        string
+	RelatedPlayer string // the player coming off for a substitution or assisting a goal
+	Result        string // score after a goal, such as 1-0
+}
+
+func (e Event) String() string {
+	s := strconv.Itoa(e.Minute)
+	if e.ExtraMinute > 0 {
+		s += "+" + strconv.Itoa(e.ExtraMinute)
+	}
+	s += "' " + e.Type
+	if e.Player != "" {
+		s += ": " + e.Player
+	}
+	if e.RelatedPlayer != "" {
+		s += " (" + e.RelatedPlayer + ")"
+	}
+	if e.Team != "" {
+		s += ", " + e.Team
+	}
+	if e.Result != "" {
+		s += ", " + e.Result
+	}
+	return s
+}
+
+// LiveFetcher fetches the score and events of a game in play.
+type LiveFetcher interface {
+	FetchLive(ctx context.Context, fixtureID int) (*Live, error)
+}
+
+type LiveAPIResponse struct {
+	Data struct {
+		State struct {
+			DeveloperName string `json:"developer_name"`
+		} `json:"state"`
+		Participants []struct {
+			ID   int    `json:"id"`
+			Name string `json:"name"`
+			Meta struct {
+				Location string `json:"location"`
+			} `json:"meta"`
+		} `json:"participants"`
+		Scores []struct {
+			Description string `json:"description"`
+			Score       struct {
+				Goals       int    `json:"goals"`
+				Participant string `json:"participant"`
+			} `json:"score"`
+		} `json:"scores"`
+		Events []LiveEventAPIResponse `json:"events"`
+	} `json:"data"`
+}
+
+type LiveEventAPIResponse struct {
+	ParticipantID     int     `json:"participant_id"`
+	PlayerName        string  `json:"player_name"`
+	RelatedPlayerName string  `json:"related_player_name"`
+	Minute            int     `json:"minute"`
+	ExtraMinute       *int    `json:"extra_minute"`
+	Result            *string `json:"result"`
+	SortOrder         int     `json:"sort_order"`
+	Type              struct {
+		Name string `json:"name"`
+	} `json:"type"`
+}
+
+// FetchLive fetches the state, the current score and the events of the game,
+// it is meant to be polled while the game is in play.
+func (c *Client) FetchLive(ctx context.Context, fixtureID int) (*Live, error) {
+	var resp LiveAPIResponse
+	if err := c.get(ctx, fmt.Sprintf("/v3/football/fixtures/%d/?include=state;scores;participants;events.type", fixtureID), &resp); err != nil {
+		return nil, err
+	}
+	return parseLive(resp), nil
+}
+
+func parseLive(resp LiveAPIResponse) *Live {
+	data := resp.Data
+	live := &Live{State: data.State.DeveloperName}
+	for _, s := range data.Scores {
+		if s.Description != currentScore {
+			continue
+		}
+		if s.Score.Participant == "home" {
+			live.HomeScore = s.Score.Goals
+		} else {
+			live.AwayScore = s.Score.Goals
+		}
+	}
+
+	teams := make(map[int]string, len(data.Participants))
+	for _, p := range data.Participants {
+		teams[p.ID] = p.Name
+	}
+	events := slices.Clone(data.Events)
+	slices.SortStableFunc(events, func(a, b LiveEventAPIResponse) int {
+		return a.SortOrder - b.SortOrder
+	})
+	for _, e := range events {
+		event := Event{
+			Minute:        e.Minute,
+			Type:          e.Type.Name,
+			Team:          teams[e.ParticipantID],
+			Player:        e.PlayerName,
+			RelatedPlayer: e.RelatedPlayerName,
+		}
+		if e.ExtraMinute != nil {
+			event.ExtraMinute = *e.ExtraMinute
+		}
+		if e.Result != nil {
+			event.Result = *e.Result
+		}
+		live.Events = append(live.Events, event)
+	}
+	return live
+}
@@ -0,0 +1,87 @@
+package fixture
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientFetchLive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v3/football/fixtures/19135003/", r.URL.Path)
+		assert.Equal(t, "key", r.Header.Get("Authorization"))
+		_, _ = w.Write([]byte(`{"data": {
+			"state": {"developer_name": "INPLAY_2ND_HALF"},
+			"participants": [
+				{"id": 19, "name": "Arsenal", "meta": {"location": "home"}},
+				{"id": 18, "name": "Chelsea", "meta": {"location": "away"}}
+			],
+			"scores": [
+				{"description": "1ST_HALF", "score": {"goals": 1, "participant": "home"}},
+				{"description": "1ST_HALF", "score": {"goals": 0, "participant": "away"}},
+				{"description": "CURRENT", "score": {"goals": 1, "participant": "home"}},
+				{"description": "CURRENT", "score": {"goals": 1, "participant": "away"}}
+			],
+			"events": [
+				{"participant_id": 18, "player_name": "Cole Palmer", "minute": 58, "extra_minute": null, "result": "1-1", "sort_order": 3, "type": {"name": "Goal"}},
+				{"participant_id": 19, "player_name": "Bukayo Saka", "related_player_name": "Martin Ødegaard", "minute": 45, "extra_minute": 2, "result": "1-0", "sort_order": 1, "type": {"name": "Goal"}},
+				{"participant_id": 18, "player_name": "Moisés Caicedo", "minute": 51, "sort_order": 2, "type": {"name": "Yellowcard"}}
+			]
+		}}`))
+	}))
+	defer server.Close()
+
+	live, err := NewClient("key", server.URL).FetchLive(context.Background(), 19135003)
+	require.NoError(t, err)
+	assert.Equal(t, &Live{
+		State:     "INPLAY_2ND_HALF",
+		HomeScore: 1,
+		AwayScore: 1,
+		Events: []Event{
+			{Minute: 45, ExtraMinute: 2, Type: "Goal", Team: "Arsenal", Player: "Bukayo Saka", RelatedPlayer: "Martin Ødegaard", Result: "1-0"},
+			{Minute: 51, Type: "Yellowcard", Team: "Chelsea", Player: "Moisés Caicedo"},
+			{Minute: 58, Type: "Goal", Team: "Chelsea", Player: "Cole Palmer", Result: "1-1"},
+		},
+	}, live)
+	assert.True(t, live.InPlay())
+}
+
+func TestEventString(t *testing.T) {
+	tests := []struct {
+		name     string
+		event    Event
+		expected string
+	}{
+		{
+			name:     "goal in added time",
+			event:    Event{Minute: 45, ExtraMinute: 2, Type: "Goal", Team: "Arsenal", Player: "Bukayo Saka", RelatedPlayer: "Martin Ødegaard", Result: "1-0"},
+			expected: "45+2' Goal: Bukayo Saka (Martin Ødegaard), Arsenal, 1-0",
+		},
+		{
+			name:     "card",
+			event:    Event{Minute: 51, Type: "Yellowcard", Team: "Chelsea", Player: "Moisés Caicedo"},
+			expected: "51' Yellowcard: Moisés Caicedo, Chelsea",
+		},
+		{
+			name:     "event without player",
+			event:    Event{Minute: 70, Type: "VAR"},
+			expected: "70' VAR",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.event.String())
+		})
+	}
+}
+
+func TestFixtureInPlay(t *testing.T) {
+	assert.True(t, (&Fixture{State: "HT"}).InPlay())
+	assert.False(t, (&Fixture{State: "NS"}).InPlay())
+	assert.False(t, (&Fixture{State: "FT"}).InPlay())
+	assert.False(t, (&Fixture{}).InPlay())
+}
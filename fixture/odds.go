@@ -0,0 +1,143 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+)
+
+// fullTimeResultMarket is the SportMonks market of the home win, draw and away win prices
+const fullTimeResultMarket = 1
+
+// outcomeLabels maps the labels of the full time result market, some bookmakers use 1, X and 2
+var outcomeLabels = map[string]string{
+	"Home": OutcomeHome,
+	"1":    OutcomeHome,
+	"Draw": OutcomeDraw,
+	"X":    OutcomeDraw,
+	"Away": OutcomeAway,
+	"2":    OutcomeAway,
+}
+
+const (
+	OutcomeHome = "Home"
+	OutcomeDraw = "Draw"
+	OutcomeAway = "Away"
+)
+
+// Odds are the pre-match prices of the full time result of a game across bookmakers.
+type Odds struct {
+	Market     string
+	Bookmakers int
+	Outcomes   []Outcome // home, draw and away
+}
+
+// Outcome is the price of an outcome in decimal odds.
+type Outcome struct {
+	Label         string
+	Average       float64
+	Best          float64
+	BestBookmaker string
+}
+
+// Decimals returns the average prices of the outcomes.
+func (o *Odds) Decimals() []float64 {
+	decimals := make([]float64, len(o.Outcomes))
+	for i, outcome := range o.Outcomes {
+		decimals[i] = outcome.Average
+	}
+	return decimals
+}
+
+// OddsFetcher fetches the pre-match odds of a game.
+type OddsFetcher interface {
+	FetchOdds(ctx context.Context, fixtureID int) (*Odds, error)
+}
+
+type OddsAPIResponse struct {
+	Data []struct {
+		BookmakerID       int    `json:"bookmaker_id"`
+		Label             string `json:"label"`
+		Value             string `json:"value"`
+		MarketDescription string `json:"market_description"`
+		Stopped           bool   `json:"stopped"`
+		Bookmaker         struct {
+			Name string `json:"name"`
+		} `json:"bookmaker"`
+	} `json:"data"`
+}
+
+// FetchOdds fetches the full time result prices of the game, nil when no bookmaker offers them.
+func (c *Client) FetchOdds(ctx context.Context, fixtureID int) (*Odds, error) {
+	var resp OddsAPIResponse
+	path := fmt.Sprintf("/v3/football/odds/pre-match/fixtures/%d/markets/%d?include=bookmaker", fixtureID, fullTimeResultMarket)
+	if err := c.get(ctx, path, &resp); err != nil {
+		return nil, err
+	}
+	return parseOdds(resp), nil
+}
+
+func parseOdds(resp OddsAPIResponse) *Odds {
+	type prices struct {
+		total         float64
+		count         int
+		best          float64
+		bestBookmaker string
+	}
+	byLabel := map[string]*prices{}
+	bookmakers := map[int]bool{}
+	market := ""
+	for _, o := range resp.Data {
+		label, ok := outcomeLabels[o.Label]
+		if !ok || o.Stopped {
+			continue
+		}
+		value, err := strconv.ParseFloat(o.Value, 64)
+		if err != nil || value <= 1 {
+			continue
+		}
+		p := byLabel[label]
+		if p == nil {
+			p = &prices{}
+			byLabel[label] = p
+		}
+		p.total += value
+		p.count++
+		if value > p.best {
+			p.best, p.bestBookmaker = value, o.Bookmaker.Name
+		}
+		bookmakers[o.BookmakerID] = true
+		market = o.MarketDescription
+	}
+	// a market missing an outcome can't be turned into probabilities
+	if len(byLabel) != 3 {
+		return nil
+	}
+
+	odds := &Odds{Market: market, Bookmakers: len(bookmakers)}
+	for _, label := range []string{OutcomeHome, OutcomeDraw, OutcomeAway} {
+		p := byLabel[label]
+		odds.Outcomes = append(odds.Outcomes, Outcome{
+			Label:         label,
+			Average:       p.total / float64(p.count),
+			Best:          p.best,
+			BestBookmaker: p.bestBookmaker,
+		})
+	}
+	return odds
+}
+
+// FakeOdds returns the same odds for every game, to run the bot locally without an odds subscription.
+type FakeOdds struct{}
+
+func (FakeOdds) FetchOdds(_ context.Context, _ int) (*Odds, error) {
+	return &Odds{
+		Market:     "Fulltime Result",
+		Bookmakers: 2,
+		Outcomes: []Outcome{
+			{Label: OutcomeHome, Average: 2.10, Best: 2.15, BestBookmaker: "bet365"},
+			{Label: OutcomeDraw, Average: 3.40, Best: 3.50, BestBookmaker: "Unibet"},
+			{Label: OutcomeAway, Average: 3.60, Best: 3.70, BestBookmaker: "bet365"},
+		},
+	}, nil
+}
@@ -0,0 +1,78 @@
+package fixture
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientFetchOdds(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+		expected *Odds
+	}{
+		{
+			name: "averaged across bookmakers",
+			response: `{"data": [
+				{"bookmaker_id": 2, "label": "Home", "value": "2.10", "market_description": "Fulltime Result", "bookmaker": {"name": "bet365"}},
+				{"bookmaker_id": 2, "label": "Draw", "value": "3.40", "market_description": "Fulltime Result", "bookmaker": {"name": "bet365"}},
+				{"bookmaker_id": 2, "label": "Away", "value": "3.60", "market_description": "Fulltime Result", "bookmaker": {"name": "bet365"}},
+				{"bookmaker_id": 9, "label": "1", "value": "2.20", "market_description": "Fulltime Result", "bookmaker": {"name": "Unibet"}},
+				{"bookmaker_id": 9, "label": "X", "value": "3.20", "market_description": "Fulltime Result", "bookmaker": {"name": "Unibet"}},
+				{"bookmaker_id": 9, "label": "2", "value": "3.60", "market_description": "Fulltime Result", "bookmaker": {"name": "Unibet"}},
+				{"bookmaker_id": 7, "label": "Home", "value": "9.00", "stopped": true, "market_description": "Fulltime Result", "bookmaker": {"name": "Stopped"}}
+			]}`,
+			expected: &Odds{
+				Market:     "Fulltime Result",
+				Bookmakers: 2,
+				Outcomes: []Outcome{
+					{Label: OutcomeHome, Average: 2.15, Best: 2.20, BestBookmaker: "Unibet"},
+					{Label: OutcomeDraw, Average: 3.30, Best: 3.40, BestBookmaker: "bet365"},
+					{Label: OutcomeAway, Average: 3.60, Best: 3.60, BestBookmaker: "bet365"},
+				},
+			},
+		},
+		{
+			name:     "no odds",
+			response: `{"data": []}`,
+		},
+		{
+			name: "missing outcome",
+			response: `{"data": [
+				{"bookmaker_id": 2, "label": "Home", "value": "2.10", "bookmaker": {"name": "bet365"}},
+				{"bookmaker_id": 2, "label": "Away", "value": "3.60", "bookmaker": {"name": "bet365"}}
+			]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/v3/football/odds/pre-match/fixtures/19135003/markets/1", r.URL.Path)
+				_, _ = w.Write([]byte(tt.response))
+			}))
+			defer server.Close()
+
+			odds, err := NewClient("key", server.URL).FetchOdds(context.Background(), 19135003)
+			require.NoError(t, err)
+			if tt.expected == nil {
+				assert.Nil(t, odds)
+				return
+			}
+			require.NotNil(t, odds)
+			assert.Equal(t, tt.expected.Market, odds.Market)
+			assert.Equal(t, tt.expected.Bookmakers, odds.Bookmakers)
+			require.Len(t, odds.Outcomes, 3)
+			for i, o := range tt.expected.Outcomes {
+				assert.Equal(t, o.Label, odds.Outcomes[i].Label)
+				assert.InDelta(t, o.Average, odds.Outcomes[i].Average, 1e-9)
+				assert.Equal(t, o.Best, odds.Outcomes[i].Best)
+				assert.Equal(t, o.BestBookmaker, odds.Outcomes[i].BestBookmaker)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package fixture
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fixtureIncludes are the includes of the fixtures listed for previews, the same context as Fetch gives the prompt
+const fixtureIncludes = "league:name;season:name;league.country;participants.country:name;venue;venue.country;state"
+
+type FixturesAPIResponse struct {
+	Data       []FixtureData `json:"data"`
+	Pagination struct {
+		HasMore bool `json:"has_more"`
+	} `json:"pagination"`
+}
+
+// UpcomingFetcher lists the fixtures starting soon of the followed teams and leagues.
+type UpcomingFetcher interface {
+	Upcoming(ctx context.Context, from, to time.Time, teamIDs, leagueIDs []int) ([]*Fixture, error)
+}
+
+// Upcoming returns the fixtures of the teams and of the leagues starting in [from, to), ordered by kick-off.
+func (c *Client) Upcoming(ctx context.Context, from, to time.Time, teamIDs, leagueIDs []int) ([]*Fixture, error) {
+	// the between endpoints take dates, the times are filtered here
+	between := fmt.Sprintf("/v3/football/fixtures/between/%s/%s", from.UTC().Format(time.DateOnly), to.UTC().Format(time.DateOnly))
+
+	var paths []string
+	for _, id := range teamIDs {
+		paths = append(paths, between+"/"+strconv.Itoa(id)+"?include="+fixtureIncludes)
+	}
+	if len(leagueIDs) > 0 {
+		ids := make([]string, len(leagueIDs))
+		for i, id := range leagueIDs {
+			ids[i] = strconv.Itoa(id)
+		}
+		paths = append(paths, between+"?include="+fixtureIncludes+"&filters=fixtureLeagues:"+strings.Join(ids, ","))
+	}
+
+	seen := map[int]bool{}
+	var fixtures []*Fixture
+	for _, path := range paths {
+		data, err := c.list(ctx, path)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range data {
+			f := toFixture(d)
+			if seen[f.ID] || f.StartingAt.Before(from) || !f.StartingAt.Before(to) {
+				continue
+			}
+			seen[f.ID] = true
+			fixtures = append(fixtures, f)
+		}
+	}
+	slices.SortFunc(fixtures, func(a, b *Fixture) int { return a.StartingAt.Compare(b.StartingAt) })
+	return fixtures, nil
+}
+
+// list fetches all pages of a fixtures endpoint.
+func (c *Client) list(ctx context.Context, path string) ([]FixtureData, error) {
+	var data []FixtureData
+	for page := 1; ; page++ {
+		var resp FixturesAPIResponse
+		if err := c.get(ctx, path+"&page="+strconv.Itoa(page), &resp); err != nil {
+			return nil, err
+		}
+		data = append(data, resp.Data...)
+		if !resp.Pagination.HasMore || len(resp.Data) == 0 {
+			return data, nil
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package fixture
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientUpcoming(t *testing.T) {
+	from := time.Date(2025, 10, 18, 12, 0, 0, 0, time.UTC)
+	to := from.Add(2 * time.Hour)
+
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path+"?"+r.URL.Query().Get("filters")+"&page="+r.URL.Query().Get("page"))
+		switch {
+		case r.URL.Path == "/v3/football/fixtures/between/2025-10-18/2025-10-18/19":
+			_, _ = w.Write([]byte(`{"data": [
+				{"id": 2, "name": "Arsenal vs Fulham", "league_id": 8, "starting_at_timestamp": 1760792400,
+				 "participants": [{"id": 19, "name": "Arsenal", "meta": {"location": "home"}}, {"id": 11, "name": "Fulham", "meta": {"location": "away"}}]},
+				{"id": 9, "name": "late game", "starting_at_timestamp": 1760814000}
+			], "pagination": {"has_more": false}}`))
+		case r.URL.Query().Get("page") == "1":
+			_, _ = w.Write([]byte(`{"data": [
+				{"id": 1, "name": "Leeds vs Everton", "league_id": 8, "starting_at_timestamp": 1760788800}
+			], "pagination": {"has_more": true}}`))
+		default:
+			_, _ = w.Write([]byte(`{"data": [
+				{"id": 2, "name": "Arsenal vs Fulham", "league_id": 8, "starting_at_timestamp": 1760792400}
+			], "pagination": {"has_more": false}}`))
+		}
+	}))
+	defer server.Close()
+
+	fixtures, err := NewClient("key", server.URL).Upcoming(context.Background(), from, to, []int{19}, []int{8, 9})
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"/v3/football/fixtures/between/2025-10-18/2025-10-18/19?&page=1",
+		"/v3/football/fixtures/between/2025-10-18/2025-10-18?fixtureLeagues:8,9&page=1",
+		"/v3/football/fixtures/between/2025-10-18/2025-10-18?fixtureLeagues:8,9&page=2",
+	}, paths)
+
+	require.Len(t, fixtures, 2)
+	assert.Equal(t, 1, fixtures[0].ID)
+	assert.Equal(t, 2, fixtures[1].ID)
+	assert.Equal(t, Team{ID: 19, Name: "Arsenal"}, fixtures[1].HomeTeam)
+	assert.Equal(t, 8, fixtures[1].League.ID)
+}
@@ -0,0 +1,184 @@
+package follow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/filter"
+	"github.com/klipach/matchguru/fixture"
+	"github.com/klipach/matchguru/sport"
+	"github.com/klipach/matchguru/store"
+	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	firestoreUserCollection = "users"
+	// Collection is the per user subcollection of followed teams and leagues, read across users by the preview job
+	Collection = "follows"
+
+	TypeTeam   = "team"
+	TypeLeague = "league"
+)
+
+var ErrUnknownEntity = errors.New("unknown team or league")
+
+// Target is a followed team or league.
+type Target struct {
+	Type string
+	ID   int
+}
+
+type doc struct {
+	Type      string    `firestore:"type"`
+	ID        int       `firestore:"id"`
+	CreatedAt time.Time `firestore:"created_at"`
+}
+
+// Validate checks that the target is a team or league with a link mapping,
+// only those have app pages and SportMonks fixtures.
+func Validate(t Target) error {
+	d := filter.DictionaryFor(sport.Football)
+	switch t.Type {
+	case TypeTeam:
+		if !d.HasTeam(t.ID) {
+			return fmt.Errorf("%w: team %d", ErrUnknownEntity, t.ID)
+		}
+	case TypeLeague:
+		if !d.HasLeague(t.ID) {
+			return fmt.Errorf("%w: league %d", ErrUnknownEntity, t.ID)
+		}
+	default:
+		return fmt.Errorf("%w: type %q", ErrUnknownEntity, t.Type)
+	}
+	return nil
+}
+
+// Add follows the team or league, following it again keeps the original date.
+func Add(ctx context.Context, userID string, t Target) (contract.Follow, error) {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return contract.Follow{}, err
+	}
+	ref := client.Collection(firestoreUserCollection).Doc(userID).Collection(Collection).Doc(docID(t))
+	d := doc{Type: t.Type, ID: t.ID, CreatedAt: time.Now().UTC()}
+	err = client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		snap, err := tx.Get(ref)
+		if err == nil {
+			return snap.DataTo(&d)
+		}
+		if status.Code(err) != codes.NotFound {
+			return err
+		}
+		return tx.Create(ref, d)
+	})
+	if err != nil {
+		return contract.Follow{}, err
+	}
+	return toContract(d), nil
+}
+
+// Remove unfollows the team or league, unfollowing something not followed is not an error.
+func Remove(ctx context.Context, userID string, t Target) error {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = client.Collection(firestoreUserCollection).Doc(userID).Collection(Collection).Doc(docID(t)).Delete(ctx)
+	return err
+}
+
+// List returns the followed teams and leagues, oldest first.
+func List(ctx context.Context, userID string) ([]contract.Follow, error) {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := client.Collection(firestoreUserCollection).Doc(userID).Collection(Collection).
+		OrderBy("created_at", firestore.Asc).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	follows := make([]contract.Follow, 0, len(docs))
+	for _, snap := range docs {
+		var d doc
+		if err := snap.DataTo(&d); err != nil {
+			return nil, err
+		}
+		follows = append(follows, toContract(d))
+	}
+	return follows, nil
+}
+
+// Followers returns the users following each team and league, across all users.
+func Followers(ctx context.Context) (map[Target][]string, error) {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	followers := map[Target][]string{}
+	iter := client.CollectionGroup(Collection).Documents(ctx)
+	defer iter.Stop()
+	for {
+		snap, err := iter.Next()
+		if err == iterator.Done {
+			return followers, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		var d doc
+		if err := snap.DataTo(&d); err != nil {
+			return nil, err
+		}
+		// users/{userID}/follows/{docID}
+		userID := snap.Ref.Parent.Parent.ID
+		t := Target{Type: d.Type, ID: d.ID}
+		followers[t] = append(followers[t], userID)
+	}
+}
+
+// Recipients returns the users following either team or the league of the fixture, sorted and without duplicates.
+func Recipients(f *fixture.Fixture, followers map[Target][]string) []string {
+	var users []string
+	for _, t := range []Target{
+		{Type: TypeTeam, ID: f.HomeTeam.ID},
+		{Type: TypeTeam, ID: f.AwayTeam.ID},
+		{Type: TypeLeague, ID: f.League.ID},
+	} {
+		users = append(users, followers[t]...)
+	}
+	slices.Sort(users)
+	return slices.Compact(users)
+}
+
+// IDs returns the followed team and league IDs, sorted.
+func IDs(followers map[Target][]string) (teamIDs, leagueIDs []int) {
+	for t := range followers {
+		switch t.Type {
+		case TypeTeam:
+			teamIDs = append(teamIDs, t.ID)
+		case TypeLeague:
+			leagueIDs = append(leagueIDs, t.ID)
+		}
+	}
+	slices.Sort(teamIDs)
+	slices.Sort(leagueIDs)
+	return teamIDs, leagueIDs
+}
+
+func docID(t Target) string {
+	return t.Type + "_" + strconv.Itoa(t.ID)
+}
+
+func toContract(d doc) contract.Follow {
+	return contract.Follow{Type: d.Type, ID: d.ID, CreatedAt: d.CreatedAt}
+}
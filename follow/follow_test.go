@@ -0,0 +1,52 @@
+package follow
+
+import (
+	"testing"
+
+	"github.com/klipach/matchguru/fixture"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		target Target
+		valid  bool
+	}{
+		{name: "league", target: Target{Type: TypeLeague, ID: 8}, valid: true},
+		{name: "unknown league", target: Target{Type: TypeLeague, ID: -1}},
+		{name: "league placeholder", target: Target{Type: TypeLeague, ID: 0}},
+		{name: "unknown team", target: Target{Type: TypeTeam, ID: -1}},
+		{name: "unknown type", target: Target{Type: "player", ID: 8}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Validate(tt.target)
+			if tt.valid {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, ErrUnknownEntity)
+			}
+		})
+	}
+}
+
+func TestRecipients(t *testing.T) {
+	followers := map[Target][]string{
+		{Type: TypeTeam, ID: 19}:  {"u1", "u2"},
+		{Type: TypeTeam, ID: 18}:  {"u3"},
+		{Type: TypeLeague, ID: 8}: {"u2", "u4"},
+		{Type: TypeTeam, ID: 1}:   {"u5"},
+	}
+	f := &fixture.Fixture{
+		HomeTeam: fixture.Team{ID: 19},
+		AwayTeam: fixture.Team{ID: 18},
+		League:   fixture.League{ID: 8},
+	}
+	assert.Equal(t, []string{"u1", "u2", "u3", "u4"}, Recipients(f, followers))
+	assert.Empty(t, Recipients(&fixture.Fixture{HomeTeam: fixture.Team{ID: 7}}, followers))
+
+	teamIDs, leagueIDs := IDs(followers)
+	assert.Equal(t, []int{1, 18, 19}, teamIDs)
+	assert.Equal(t, []int{8}, leagueIDs)
+}
@@ -0,0 +1,91 @@
+package matchguru
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	fbauth "firebase.google.com/go/v4/auth"
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/follow"
+	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/notify"
+)
+
+func listFollows(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	follows, err := follow.List(r.Context(), token.UID)
+	if err != nil {
+		log.LoggerFromContext(r.Context()).Error("error while listing follows", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, contract.FollowsResponse{Follows: follows})
+}
+
+func addFollow(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	target, ok := followTarget(r)
+	if !ok {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	f, err := follow.Add(r.Context(), token.UID, target)
+	if err != nil {
+		log.LoggerFromContext(r.Context()).Error("error while following", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, f)
+}
+
+func removeFollow(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	target, ok := followTarget(r)
+	if !ok {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	if err := follow.Remove(r.Context(), token.UID, target); err != nil {
+		log.LoggerFromContext(r.Context()).Error("error while unfollowing", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// followTarget reads the team or league from the path, only IDs of the link dictionaries can be followed.
+func followTarget(r *http.Request) (follow.Target, bool) {
+	id, err := strconv.Atoi(r.PathValue("id"))
+	if err != nil {
+		return follow.Target{}, false
+	}
+	target := follow.Target{Type: r.PathValue("type"), ID: id}
+	if err := follow.Validate(target); err != nil {
+		return follow.Target{}, false
+	}
+	return target, true
+}
+
+func registerDevice(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	var req contract.DeviceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || strings.TrimSpace(req.Token) == "" {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	if err := (notify.FirestoreDevices{}).Register(r.Context(), token.UID, strings.TrimSpace(req.Token)); err != nil {
+		log.LoggerFromContext(r.Context()).Error("error while registering device", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// unregisterDevice is called on sign out, so the previews don't reach the next user of the device.
+func unregisterDevice(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	if err := (notify.FirestoreDevices{}).Remove(r.Context(), token.UID, []string{r.PathValue("token")}); err != nil {
+		log.LoggerFromContext(r.Context()).Error("error while unregistering device", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
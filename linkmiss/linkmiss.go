@@ -0,0 +1,107 @@
+package linkmiss
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/klipach/matchguru/store"
+)
+
+// Collection aggregates the internal link names without a mapping, one document per name.
+// It holds no user data, only team and league names written by the model.
+const Collection = "unresolved_links"
+
+// maxDocIDLength is the longest document ID Firestore accepts, in bytes.
+const maxDocIDLength = 1500
+
+// Miss is an unresolved internal link name and how often answers used it.
+type Miss struct {
+	Name      string    `firestore:"name"`
+	Count     int64     `firestore:"count"`
+	LastSeen  time.Time `firestore:"last_seen"`
+	Dismissed bool      `firestore:"dismissed"` // reviewed in cmd/linkmine, no longer counted
+}
+
+// DocID is the document ID of the name, names may contain slashes which are not allowed in IDs.
+// Firestore also rejects the IDs ".", ".." and "__*__", so their first character is
+// percent-encoded, which url.PathEscape never does to dots and underscores.
+func DocID(name string) string {
+	id := url.PathEscape(name)
+	if id == "." || id == ".." || (len(id) >= 4 && strings.HasPrefix(id, "__") && strings.HasSuffix(id, "__")) {
+		id = fmt.Sprintf("%%%02X", id[0]) + id[1:]
+	}
+	return id
+}
+
+// Dismiss stops counting the name, the document is kept so Record doesn't create it again.
+func Dismiss(ctx context.Context, client *firestore.Client, name string) error {
+	_, err := client.Collection(Collection).Doc(DocID(name)).Set(ctx, map[string]any{
+		"name":      name,
+		"dismissed": true,
+	}, firestore.MergeAll)
+	return err
+}
+
+// Record counts the unresolved names of an answer, dismissed names are skipped.
+func Record(ctx context.Context, names []string) error {
+	counts := countNames(names)
+	if len(counts) == 0 {
+		return nil
+	}
+	client, err := store.Client(ctx)
+	if err != nil {
+		return err
+	}
+
+	unique := slices.Collect(maps.Keys(counts))
+	refs := make([]*firestore.DocumentRef, len(unique))
+	for i, name := range unique {
+		refs[i] = client.Collection(Collection).Doc(DocID(name))
+	}
+	now := time.Now()
+	return client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		docs, err := tx.GetAll(refs)
+		if err != nil {
+			return err
+		}
+		// the snapshots are in the order of the refs
+		for i, doc := range docs {
+			var m Miss
+			if doc.Exists() {
+				if err := doc.DataTo(&m); err != nil {
+					return err
+				}
+			}
+			if m.Dismissed {
+				continue
+			}
+			name := unique[i]
+			if err := tx.Set(doc.Ref, map[string]any{
+				"name":      name,
+				"count":     firestore.Increment(counts[name]),
+				"last_seen": now,
+				"dismissed": false,
+			}, firestore.MergeAll); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func countNames(names []string) map[string]int {
+	counts := map[string]int{}
+	for _, name := range names {
+		// longer names can't be stored, they are not team or league names anyway
+		if name != "" && len(DocID(name)) <= maxDocIDLength {
+			counts[name]++
+		}
+	}
+	return counts
+}
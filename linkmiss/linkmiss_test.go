@@ -0,0 +1,42 @@
+package linkmiss
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCountNames(t *testing.T) {
+	assert.Equal(t, map[string]int{"russian premier league": 2, "zenit": 1}, countNames([]string{"russian premier league", "zenit", "", "russian premier league"}))
+	assert.Empty(t, countNames(nil))
+	assert.Empty(t, countNames([]string{strings.Repeat("a", maxDocIDLength+1)}))
+}
+
+func TestDocID(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected string
+	}{
+		{name: "zenit", expected: "zenit"},
+		{name: "bosnia/herzegovina", expected: "bosnia%2Fherzegovina"},
+		{name: "st. pauli", expected: "st.%20pauli"},
+		{name: ".", expected: "%2E"},
+		{name: "..", expected: "%2E."},
+		{name: "__name__", expected: "%5F_name__"},
+		{name: "___", expected: "___"},
+		{name: "__init", expected: "__init"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			id := DocID(tt.name)
+			assert.Equal(t, tt.expected, id)
+			// the encoding stays reversible, so distinct names get distinct IDs
+			name, err := url.PathUnescape(id)
+			require.NoError(t, err)
+			assert.Equal(t, tt.name, name)
+		})
+	}
+}
@@ -0,0 +1,118 @@
+package matchguru
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/fixture"
+	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/sport"
+	"github.com/klipach/matchguru/sse"
+	"github.com/klipach/matchguru/telemetry"
+)
+
+// liveHeartbeatInterval is how long the stream may stay silent between score events,
+// proxies and load balancers close connections idle for longer than about a minute
+const liveHeartbeatInterval = 15 * time.Second
+
+// fetchLive adds the score and events to a fixture in play, web search results lag behind them.
+// Sports without live API and failures leave the fixture as is.
+func (b *bot) fetchLive(ctx context.Context, sp sport.Sport, f *fixture.Fixture) {
+	fetcher, ok := b.fixtures[sp].(fixture.LiveFetcher)
+	if !ok || !f.InPlay() {
+		return
+	}
+	logger := log.LoggerFromContext(ctx)
+	ctx, span := tracer.Start(ctx, "bot.live")
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.FixtureFetchTimeout.Duration)
+	defer cancel()
+
+	live, err := fetcher.FetchLive(ctx, f.ID)
+	endSpan(span, err)
+	if err != nil {
+		telemetry.RecordFixtureError(ctx)
+		logger.Error("error while fetching live fixture", slog.Int(gameIDLogField, f.ID), slog.String(ErrorMsgLogField, err.Error()))
+		return
+	}
+	f.Live = live
+	logger.Info("live fixture fetched", slog.String("state", live.State), slog.Int("homeScore", live.HomeScore), slog.Int("awayScore", live.AwayScore))
+}
+
+// pushLiveUpdates keeps the answer stream open while the game is in play and sends the score whenever
+// the state, the score or the events change. It returns once the game is over, the client is gone
+// or the configured duration has passed.
+func (b *bot) pushLiveUpdates(ctx context.Context, sw *sse.Writer, sp sport.Sport, gameID int, live *fixture.Live) {
+	fetcher, ok := b.fixtures[sp].(fixture.LiveFetcher)
+	if !ok || b.cfg.LiveUpdatesDuration.Duration == 0 {
+		return
+	}
+	logger := log.LoggerFromContext(ctx)
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.LiveUpdatesDuration.Duration)
+	defer cancel()
+
+	// the first event tells the client the stream stays open
+	if err := sw.Event(contract.EventScore, liveScore(gameID, live)); err != nil {
+		logger.Error("error while sending score event", slog.String(ErrorMsgLogField, err.Error()))
+		return
+	}
+	ticker := time.NewTicker(b.cfg.LivePollInterval.Duration)
+	defer ticker.Stop()
+	heartbeat := time.NewTicker(liveHeartbeatInterval)
+	defer heartbeat.Stop()
+	for live.InPlay() {
+		select {
+		case <-ctx.Done():
+			logger.Info("live updates stopped", slog.String("reason", context.Cause(ctx).Error()))
+			return
+		case <-heartbeat.C:
+			if err := sw.Heartbeat(); err != nil {
+				logger.Error("error while sending heartbeat", slog.String(ErrorMsgLogField, err.Error()))
+				return
+			}
+			continue
+		case <-ticker.C:
+		}
+
+		fctx, fcancel := context.WithTimeout(ctx, b.cfg.FixtureFetchTimeout.Duration)
+		current, err := fetcher.FetchLive(fctx, gameID)
+		fcancel()
+		if err != nil {
+			// the next poll may succeed
+			telemetry.RecordFixtureError(ctx)
+			logger.Warn("error while polling live fixture", slog.String(ErrorMsgLogField, err.Error()))
+			continue
+		}
+		if !liveChanged(live, current) {
+			continue
+		}
+		live = current
+		if err := sw.Event(contract.EventScore, liveScore(gameID, live)); err != nil {
+			logger.Error("error while sending score event", slog.String(ErrorMsgLogField, err.Error()))
+			return
+		}
+		heartbeat.Reset(liveHeartbeatInterval)
+	}
+}
+
+func liveChanged(prev, cur *fixture.Live) bool {
+	return prev.State != cur.State ||
+		prev.HomeScore != cur.HomeScore ||
+		prev.AwayScore != cur.AwayScore ||
+		len(prev.Events) != len(cur.Events)
+}
+
+func liveScore(gameID int, live *fixture.Live) contract.LiveScore {
+	score := contract.LiveScore{
+		GameID:    gameID,
+		State:     live.State,
+		InPlay:    live.InPlay(),
+		HomeScore: live.HomeScore,
+		AwayScore: live.AwayScore,
+	}
+	if n := len(live.Events); n > 0 {
+		score.LastEvent = live.Events[n-1].String()
+	}
+	return score
+}
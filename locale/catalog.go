@@ -0,0 +1,122 @@
+package locale
+
+import (
+	"github.com/klipach/matchguru/sport"
+	"golang.org/x/text/language"
+)
+
+var catalog = map[language.Tag]*Messages{
+	language.English: {
+		Sports: map[sport.Sport]string{sport.Football: "soccer", sport.Cricket: "cricket"},
+		Refusals: []string{
+			"I appreciate your question, but I'm specialized exclusively in %[1]s. I'd be happy to help you with any %[1]s-related queries instead!",
+			"That's outside my area of expertise. As a dedicated %[1]s analyst, I focus only on %[1]s matters. What would you like to know about %[1]s today?",
+			"I'm designed to be your %[1]s expert only. Let me help you with %[1]s predictions, player analysis, or match insights instead!",
+		},
+		RefusalMarkers: []string{
+			"specialized exclusively in %[1]s",
+			"specialize exclusively in %[1]s",
+			"outside my area of expertise",
+			"designed to be your %[1]s expert only",
+		},
+		InternalError:   "Something went wrong, please try again.",
+		TooManyRequests: "You are sending messages too fast, please try again later.",
+		Conflict:        "This message can't be changed anymore.",
+	},
+	language.Spanish: {
+		Sports: map[sport.Sport]string{sport.Football: "fútbol", sport.Cricket: "críquet"},
+		Refusals: []string{
+			"Agradezco tu pregunta, pero estoy especializado exclusivamente en %[1]s. ¡Con gusto te ayudo con cualquier consulta sobre %[1]s!",
+			"Eso está fuera de mi área de especialización. Como analista dedicado, me centro solo en el %[1]s. ¿Qué te gustaría saber hoy sobre %[1]s?",
+			"Estoy diseñado para ser tu experto en %[1]s y nada más. ¡Déjame ayudarte con pronósticos, análisis de jugadores o detalles de partidos de %[1]s!",
+		},
+		RefusalMarkers: []string{
+			"especializado exclusivamente en %[1]s",
+			"fuera de mi área de especialización",
+			"experto en %[1]s y nada más",
+		},
+		InternalError:   "Algo salió mal, inténtalo de nuevo.",
+		TooManyRequests: "Estás enviando mensajes demasiado rápido, inténtalo más tarde.",
+		Conflict:        "Este mensaje ya no se puede cambiar.",
+	},
+	language.Portuguese: {
+		Sports: map[sport.Sport]string{sport.Football: "futebol", sport.Cricket: "críquete"},
+		Refusals: []string{
+			"Agradeço a sua pergunta, mas sou especializado exclusivamente em %[1]s. Terei todo o gosto em ajudar com qualquer dúvida sobre %[1]s!",
+			"Isso está fora da minha área de especialização. Como analista dedicado, foco apenas em %[1]s. O que gostaria de saber sobre %[1]s hoje?",
+			"Fui criado para ser o seu especialista em %[1]s e nada mais. Deixe-me ajudar com previsões, análises de jogadores ou detalhes de partidas de %[1]s!",
+		},
+		RefusalMarkers: []string{
+			"especializado exclusivamente em %[1]s",
+			"fora da minha área de especialização",
+			"especialista em %[1]s e nada mais",
+		},
+		InternalError:   "Algo deu errado, tente novamente.",
+		TooManyRequests: "Você está enviando mensagens rápido demais, tente novamente mais tarde.",
+		Conflict:        "Esta mensagem não pode mais ser alterada.",
+	},
+	language.French: {
+		Sports: map[sport.Sport]string{sport.Football: "football", sport.Cricket: "cricket"},
+		Refusals: []string{
+			"Merci pour votre question, mais je suis spécialisé exclusivement dans le %[1]s. Je serai ravi de vous aider pour toute question sur le %[1]s !",
+			"Cela sort de mon domaine d'expertise. En tant qu'analyste dédié, je me concentre uniquement sur le %[1]s. Que souhaitez-vous savoir sur le %[1]s aujourd'hui ?",
+			"Je suis conçu pour être uniquement votre expert en %[1]s. Laissez-moi vous aider avec des pronostics, des analyses de joueurs ou des informations sur les matchs de %[1]s !",
+		},
+		RefusalMarkers: []string{
+			"spécialisé exclusivement dans le %[1]s",
+			"sort de mon domaine d'expertise",
+			"uniquement votre expert en %[1]s",
+		},
+		InternalError:   "Une erreur s'est produite, veuillez réessayer.",
+		TooManyRequests: "Vous envoyez des messages trop rapidement, veuillez réessayer plus tard.",
+		Conflict:        "Ce message ne peut plus être modifié.",
+	},
+	language.German: {
+		Sports: map[sport.Sport]string{sport.Football: "Fußball", sport.Cricket: "Cricket"},
+		Refusals: []string{
+			"Danke für deine Frage, aber ich bin ausschließlich auf %[1]s spezialisiert. Gerne helfe ich dir bei allen Fragen rund um %[1]s!",
+			"Das liegt außerhalb meines Fachgebiets. Als engagierter Analyst konzentriere ich mich nur auf %[1]s. Was möchtest du heute über %[1]s wissen?",
+			"Ich bin ausschließlich als dein %[1]s-Experte gedacht. Lass mich dir stattdessen mit Prognosen, Spieleranalysen oder Spielinfos zu %[1]s helfen!",
+		},
+		RefusalMarkers: []string{
+			"ausschließlich auf %[1]s spezialisiert",
+			"außerhalb meines fachgebiets",
+			"ausschließlich als dein %[1]s-experte",
+		},
+		InternalError:   "Etwas ist schiefgelaufen, bitte versuche es erneut.",
+		TooManyRequests: "Du sendest zu schnell Nachrichten, bitte versuche es später erneut.",
+		Conflict:        "Diese Nachricht kann nicht mehr geändert werden.",
+	},
+	language.Italian: {
+		Sports: map[sport.Sport]string{sport.Football: "calcio", sport.Cricket: "cricket"},
+		Refusals: []string{
+			"Apprezzo la tua domanda, ma sono specializzato esclusivamente nel %[1]s. Sarò felice di aiutarti con qualsiasi domanda sul %[1]s!",
+			"Questo è al di fuori della mia area di competenza. Come analista dedicato, mi occupo solo di %[1]s. Cosa vorresti sapere oggi sul %[1]s?",
+			"Sono stato creato per essere solo il tuo esperto di %[1]s. Lascia che ti aiuti con pronostici, analisi dei giocatori o approfondimenti sulle partite di %[1]s!",
+		},
+		RefusalMarkers: []string{
+			"specializzato esclusivamente nel %[1]s",
+			"al di fuori della mia area di competenza",
+			"solo il tuo esperto di %[1]s",
+		},
+		InternalError:   "Qualcosa è andato storto, riprova.",
+		TooManyRequests: "Stai inviando messaggi troppo velocemente, riprova più tardi.",
+		Conflict:        "Questo messaggio non può più essere modificato.",
+	},
+	language.Ukrainian: {
+		Sports: map[sport.Sport]string{sport.Football: "футбол", sport.Cricket: "крикет"},
+		Refusals: []string{
+			"Дякую за запитання, але моя спеціалізація — виключно %[1]s. Із задоволенням допоможу з будь-якими запитаннями про %[1]s!",
+			"Це поза межами моєї експертизи. Як аналітик, я відповідаю лише на запитання про %[1]s. Що б ви хотіли дізнатися про %[1]s сьогодні?",
+			"Я створений бути лише вашим експертом, і моя тема — %[1]s. Дозвольте допомогти з прогнозами, аналізом гравців або матчів — усе про %[1]s!",
+		},
+		RefusalMarkers: []string{
+			"моя спеціалізація — виключно %[1]s",
+			"поза межами моєї експертизи",
+			"моя тема — %[1]s",
+		},
+		InternalError:   "Щось пішло не так, спробуйте ще раз.",
+		TooManyRequests: "Ви надсилаєте повідомлення надто швидко, спробуйте пізніше.",
+		Conflict:        "Це повідомлення вже не можна змінити.",
+	},
+}
@@ -0,0 +1,115 @@
+// Package locale resolves the language answers are written in and holds the messages
+// the bot sends in that language, the refusal templates of the prompts and the stream errors.
+package locale
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/klipach/matchguru/sport"
+	"golang.org/x/text/language"
+	"golang.org/x/text/language/display"
+)
+
+// Default is the language of users who asked for none.
+var Default = language.English
+
+// Messages are the texts of a language, refusal templates and markers take the sport name as %[1]s.
+type Messages struct {
+	Sports map[sport.Sport]string
+	// Refusals are the polite refusal templates the prompts ask the model to use word for word
+	Refusals []string
+	// RefusalMarkers are phrases of the refusals in lowercase, telling a refusal from an answer
+	RefusalMarkers []string
+
+	InternalError   string
+	TooManyRequests string
+	Conflict        string
+}
+
+// supported lists the languages with messages, the first is the fallback of the others.
+var supported = []language.Tag{
+	language.English,
+	language.Spanish,
+	language.Portuguese,
+	language.French,
+	language.German,
+	language.Italian,
+	language.Ukrainian,
+}
+
+var (
+	matcher = language.NewMatcher(supported)
+	mul     = language.MustParse("mul")
+)
+
+// Parse reads the language of a request, such as "uk" or "pt-BR".
+func Parse(s string) (language.Tag, error) {
+	tag, err := language.Parse(strings.TrimSpace(s))
+	if err != nil {
+		return language.Und, fmt.Errorf("invalid language %q: %w", s, err)
+	}
+	return tag, nil
+}
+
+// FromAcceptLanguage returns the preferred language of an Accept-Language header, reporting false when there is none.
+func FromAcceptLanguage(header string) (language.Tag, bool) {
+	tags, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		return language.Und, false
+	}
+	for _, tag := range tags {
+		// "*" is parsed as multiple languages
+		if tag != language.Und && tag != mul {
+			return tag, true
+		}
+	}
+	return language.Und, false
+}
+
+// Name is the English name of the language, as the prompts are written in English.
+func Name(tag language.Tag) string {
+	if tag == language.Und {
+		tag = Default
+	}
+	return display.English.Tags().Name(tag)
+}
+
+// For returns the messages of the closest supported language, English when none is close.
+func For(tag language.Tag) *Messages {
+	_, i, confidence := matcher.Match(tag)
+	if confidence == language.No {
+		i = 0
+	}
+	return catalog[supported[i]]
+}
+
+// RefusalsFor returns the refusal templates about the sport.
+func (m *Messages) RefusalsFor(sp sport.Sport) []string {
+	return format(m.Refusals, m.Sports[sp])
+}
+
+// AllRefusalMarkers returns the refusal markers of all languages and sports.
+func AllRefusalMarkers() []string {
+	var markers []string
+	for _, tag := range supported {
+		m := catalog[tag]
+		for _, sp := range sport.All {
+			markers = append(markers, format(m.RefusalMarkers, strings.ToLower(m.Sports[sp]))...)
+		}
+	}
+	slices.Sort(markers)
+	return slices.Compact(markers)
+}
+
+func format(templates []string, sportName string) []string {
+	texts := make([]string, 0, len(templates))
+	for _, t := range templates {
+		if strings.Contains(t, "%[1]s") {
+			t = fmt.Sprintf(t, sportName)
+		}
+		texts = append(texts, t)
+	}
+	return texts
+}
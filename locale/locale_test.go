@@ -0,0 +1,69 @@
+package locale
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/klipach/matchguru/sport"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestCatalog(t *testing.T) {
+	require.Len(t, catalog, len(supported))
+	for _, tag := range supported {
+		m := catalog[tag]
+		require.NotNil(t, m, tag.String())
+		assert.NotEmpty(t, m.InternalError, tag.String())
+		assert.NotEmpty(t, m.TooManyRequests, tag.String())
+		assert.NotEmpty(t, m.Conflict, tag.String())
+		for _, sp := range sport.All {
+			require.NotEmpty(t, m.Sports[sp], "%s %s", tag, sp)
+			markers := format(m.RefusalMarkers, strings.ToLower(m.Sports[sp]))
+			// every refusal must be detected as one
+			for _, refusal := range m.RefusalsFor(sp) {
+				assert.NotContains(t, refusal, "%!", "%s %s", tag, sp)
+				found := false
+				for _, marker := range markers {
+					found = found || strings.Contains(strings.ToLower(refusal), marker)
+				}
+				assert.True(t, found, "no marker in %q", refusal)
+			}
+		}
+	}
+}
+
+func TestFor(t *testing.T) {
+	tests := []struct {
+		tag      string
+		expected language.Tag
+	}{
+		{"uk", language.Ukrainian},
+		{"pt-BR", language.Portuguese},
+		{"de-AT", language.German},
+		{"en-GB", language.English},
+		{"ja", language.English},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tag, func(t *testing.T) {
+			assert.Same(t, catalog[tt.expected], For(language.MustParse(tt.tag)))
+		})
+	}
+}
+
+func TestFromAcceptLanguage(t *testing.T) {
+	tag, ok := FromAcceptLanguage("uk-UA,uk;q=0.9,en-US;q=0.8")
+	require.True(t, ok)
+	assert.Equal(t, "uk-UA", tag.String())
+
+	_, ok = FromAcceptLanguage("")
+	assert.False(t, ok)
+	_, ok = FromAcceptLanguage("*")
+	assert.False(t, ok)
+}
+
+func TestName(t *testing.T) {
+	assert.Equal(t, "Ukrainian", Name(language.Ukrainian))
+	assert.Equal(t, "English", Name(language.Und))
+}
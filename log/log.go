@@ -3,86 +3,207 @@ package log
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"log/slog"
 	"os"
+	"runtime"
+	"strconv"
+	"sync"
 	"time"
 )
 
+// special fields of the Google Cloud structured logging format,
+// see https://cloud.google.com/logging/docs/structured-logging#special-payload-fields
+const (
+	severityField       = "severity"
+	messageField        = "message"
+	timeField           = "time"
+	sourceLocationField = "logging.googleapis.com/sourceLocation"
+	traceField          = "logging.googleapis.com/trace"
+	spanIDField         = "logging.googleapis.com/spanId"
+	traceSampledField   = "logging.googleapis.com/trace_sampled"
+)
+
 type ctxKey struct{}
 
+// level is shared by all handlers created with NewCloudLoggingHandler, set from config.
+var level = new(slog.LevelVar)
+
+// SetLevel sets the minimum level logged by the handlers created with NewCloudLoggingHandler.
+func SetLevel(l slog.Level) {
+	level.Set(l)
+}
+
 // CloudLoggingHandler is a slog.Handler implementation for Google Cloud Functions.
 type CloudLoggingHandler struct {
+	out   *output
+	level slog.Leveler
+	// trace of the request the logger is bound to, see WithTrace
+	trace *Trace
+	// ops are the groups and attributes added with WithGroup and WithAttrs, in call order
+	ops []handlerOp
+}
+
+// handlerOp is either a group opened with WithGroup or attributes added with WithAttrs.
+type handlerOp struct {
+	group string
 	attrs []slog.Attr
 }
 
+type groupRef struct {
+	parent map[string]any
+	key    string
+}
+
+// output serializes writes, so concurrent log lines are not interleaved.
+type output struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+var stdout = &output{w: os.Stdout}
+
 // NewCloudLoggingHandler creates a new handler that writes logs in Google Cloud structured format.
 func NewCloudLoggingHandler() *CloudLoggingHandler {
-	return &CloudLoggingHandler{}
+	return &CloudLoggingHandler{out: stdout, level: level}
 }
 
 // Handle processes log records.
 func (h *CloudLoggingHandler) Handle(ctx context.Context, r slog.Record) error {
-	// Extract trace info if available
-	traceID := getTraceID(ctx)
+	t := r.Time
+	if t.IsZero() {
+		t = time.Now()
+	}
 
 	// Prepare log entry in Google Cloud structured logging format
 	entry := map[string]any{
-		"severity": r.Level.String(),
-		"time":     time.Now().Format(time.RFC3339),
-		"message":  r.Message,
+		severityField: severity(r.Level),
+		timeField:     t.Format(time.RFC3339Nano),
+		messageField:  r.Message,
 	}
-
-	// Include trace ID if available
-	if traceID != "" {
-		entry["logging.googleapis.com/trace"] = traceID
+	if r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		entry[sourceLocationField] = map[string]any{
+			"file":     frame.File,
+			"line":     strconv.Itoa(frame.Line),
+			"function": frame.Function,
+		}
 	}
-
-	// Add handler attributes first
-	for _, attr := range h.attrs {
-		entry[attr.Key] = attr.Value.Any()
+	// the context passed to the *Context logging methods is more specific than the bound trace
+	if t, ok := TraceFromContext(ctx); ok {
+		t.addTo(entry)
+	} else if h.trace != nil {
+		h.trace.addTo(entry)
 	}
 
-	// Add record attributes
+	// handler attributes first, each group nests the attributes added after it
+	current := entry
+	var groups []groupRef
+	for _, op := range h.ops {
+		if op.group != "" {
+			group := map[string]any{}
+			current[op.group] = group
+			groups = append(groups, groupRef{parent: current, key: op.group})
+			current = group
+			continue
+		}
+		addAttrs(current, op.attrs)
+	}
 	r.Attrs(func(attr slog.Attr) bool {
-		entry[attr.Key] = attr.Value.Any()
+		addAttr(current, attr)
 		return true
 	})
+	// groups without attributes are omitted, innermost first
+	for i := len(groups) - 1; i >= 0; i-- {
+		g := groups[i]
+		if len(g.parent[g.key].(map[string]any)) > 0 {
+			break
+		}
+		delete(g.parent, g.key)
+	}
 
 	// Encode as JSON and write to stdout
 	jsonData, err := json.Marshal(entry)
 	if err != nil {
 		return err
 	}
-	os.Stdout.Write(jsonData)
-	os.Stdout.Write([]byte("\n"))
-	return nil
+	h.out.mu.Lock()
+	defer h.out.mu.Unlock()
+	_, err = h.out.w.Write(append(jsonData, '\n'))
+	return err
 }
 
-// Enabled always returns true, so all log levels are handled.
-func (h *CloudLoggingHandler) Enabled(_ context.Context, _ slog.Level) bool {
-	return true
+// Enabled reports whether the level is at least the configured minimum level.
+func (h *CloudLoggingHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return l >= h.level.Level()
 }
 
 // WithAttrs returns a new handler with additional attributes.
 func (h *CloudLoggingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
-	newAttrs := make([]slog.Attr, len(h.attrs)+len(attrs))
-	copy(newAttrs, h.attrs)
-	copy(newAttrs[len(h.attrs):], attrs)
-	return &CloudLoggingHandler{attrs: newAttrs}
+	if len(attrs) == 0 {
+		return h
+	}
+	return h.with(handlerOp{attrs: attrs})
+}
+
+// WithGroup returns a new handler nesting the attributes added later under the group.
+func (h *CloudLoggingHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return h.with(handlerOp{group: name})
+}
+
+func (h *CloudLoggingHandler) with(op handlerOp) *CloudLoggingHandler {
+	ops := make([]handlerOp, len(h.ops), len(h.ops)+1)
+	copy(ops, h.ops)
+	return &CloudLoggingHandler{out: h.out, level: h.level, trace: h.trace, ops: append(ops, op)}
 }
 
-// WithGroup returns the same handler, as grouping is not implemented.
-func (h *CloudLoggingHandler) WithGroup(_ string) slog.Handler {
-	return h
+func addAttrs(m map[string]any, attrs []slog.Attr) {
+	for _, attr := range attrs {
+		addAttr(m, attr)
+	}
+}
+
+func addAttr(m map[string]any, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return
+	}
+	if attr.Value.Kind() != slog.KindGroup {
+		m[attr.Key] = attr.Value.Any()
+		return
+	}
+	groupAttrs := attr.Value.Group()
+	if len(groupAttrs) == 0 {
+		return
+	}
+	// an attribute group without key is inlined
+	if attr.Key == "" {
+		addAttrs(m, groupAttrs)
+		return
+	}
+	group := map[string]any{}
+	addAttrs(group, groupAttrs)
+	m[attr.Key] = group
 }
 
-// getTraceID extracts the Google Cloud Trace ID from the context.
-func getTraceID(ctx context.Context) string {
-	if ctx == nil {
-		return ""
+// severity maps slog levels to Cloud Logging severities,
+// levels between the standard ones get the severity of the level below.
+func severity(l slog.Level) string {
+	switch {
+	case l < slog.LevelInfo:
+		return "DEBUG"
+	case l < slog.LevelWarn:
+		return "INFO"
+	case l < slog.LevelError:
+		return "WARNING"
+	case l < slog.LevelError+4:
+		return "ERROR"
+	default:
+		return "CRITICAL"
 	}
-	traceID, _ := ctx.Value("traceID").(string)
-	return traceID
 }
 
 func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
@@ -0,0 +1,106 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestLogger(l slog.Level) (*slog.Logger, *bytes.Buffer) {
+	var buf bytes.Buffer
+	return slog.New(&CloudLoggingHandler{out: &output{w: &buf}, level: l}), &buf
+}
+
+func decodeEntry(t *testing.T, buf *bytes.Buffer) map[string]any {
+	t.Helper()
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &entry))
+	buf.Reset()
+	return entry
+}
+
+func TestSeverity(t *testing.T) {
+	tests := []struct {
+		level    slog.Level
+		expected string
+	}{
+		{slog.LevelDebug, "DEBUG"},
+		{slog.LevelInfo, "INFO"},
+		{slog.LevelInfo + 2, "INFO"},
+		{slog.LevelWarn, "WARNING"},
+		{slog.LevelError, "ERROR"},
+		{slog.LevelError + 4, "CRITICAL"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.level.String(), func(t *testing.T) {
+			assert.Equal(t, tt.expected, severity(tt.level))
+		})
+	}
+}
+
+func TestHandlerLevel(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelWarn)
+	logger.Info("skipped")
+	assert.Empty(t, buf.String())
+
+	logger.Warn("logged")
+	entry := decodeEntry(t, buf)
+	assert.Equal(t, "WARNING", entry[severityField])
+	assert.Equal(t, "logged", entry[messageField])
+}
+
+func TestHandlerGroups(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelInfo)
+	logger.With("a", 1).WithGroup("g").With("b", 2).WithGroup("h").Info("msg", "c", 3, slog.Group("i", "d", 4), slog.Group("empty"))
+	entry := decodeEntry(t, buf)
+	assert.Equal(t, float64(1), entry["a"])
+	assert.Equal(t, map[string]any{
+		"b": float64(2),
+		"h": map[string]any{
+			"c": float64(3),
+			"i": map[string]any{"d": float64(4)},
+		},
+	}, entry["g"])
+
+	// groups without attributes are omitted
+	logger.WithGroup("g").WithGroup("h").Info("msg")
+	entry = decodeEntry(t, buf)
+	assert.NotContains(t, entry, "g")
+}
+
+func TestHandlerSourceLocation(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelInfo)
+	logger.Info("msg")
+	entry := decodeEntry(t, buf)
+	source, ok := entry[sourceLocationField].(map[string]any)
+	require.True(t, ok)
+	assert.True(t, strings.HasSuffix(source["file"].(string), "log_test.go"))
+	assert.Equal(t, "github.com/klipach/matchguru/log.TestHandlerSourceLocation", source["function"])
+	assert.NotEmpty(t, source["line"])
+}
+
+func TestHandlerTrace(t *testing.T) {
+	logger, buf := newTestLogger(slog.LevelInfo)
+	trace := Trace{ProjectID: "project", TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true}
+	ctx := WithTrace(WithLogger(context.Background(), logger.With("a", 1)), trace)
+
+	LoggerFromContext(ctx).Info("msg")
+	entry := decodeEntry(t, buf)
+	assert.Equal(t, "projects/project/traces/4bf92f3577b34da6a3ce929d0e0e4736", entry[traceField])
+	assert.Equal(t, "00f067aa0ba902b7", entry[spanIDField])
+	assert.Equal(t, true, entry[traceSampledField])
+	assert.Equal(t, float64(1), entry["a"])
+
+	// the trace in the context of *Context calls wins over the bound one
+	other := Trace{TraceID: "0af7651916cd43dd8448eb211c80319c"}
+	LoggerFromContext(ctx).InfoContext(WithTrace(context.Background(), other), "msg")
+	entry = decodeEntry(t, buf)
+	assert.Equal(t, "0af7651916cd43dd8448eb211c80319c", entry[traceField])
+	assert.NotContains(t, entry, spanIDField)
+}
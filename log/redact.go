@@ -0,0 +1,90 @@
+package log
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"math/rand/v2"
+	"regexp"
+	"slices"
+)
+
+const defaultMaxBodyLength = 500
+
+// Policy controls how much of the user data ends up in the logs.
+type Policy struct {
+	// MaxBodyLength truncates logged bodies to this many runes, 0 means the default
+	MaxBodyLength int `json:"max_body_length"`
+	// BodySampleRate is the share of requests, from 0 to 1, logged with full bodies
+	BodySampleRate float64 `json:"body_sample_rate"`
+	// HashUserIDs replaces user IDs in logs with a hash
+	HashUserIDs bool `json:"hash_user_ids"`
+	// DebugUsers are the user IDs always logged with full bodies, while investigating an issue
+	DebugUsers []string `json:"debug_users"`
+}
+
+var (
+	policy = Policy{MaxBodyLength: defaultMaxBodyLength}
+
+	emailRegex = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	// international numbers with a leading +, or the 3-3-4 local format,
+	// dates and scores don't have either shape
+	phoneRegex = regexp.MustCompile(`\+\d[\d\s().-]{6,}\d|\(?\b\d{3}\)?[\s.-]\d{3}[\s.-]\d{4}\b`)
+)
+
+type verboseKey struct{}
+
+// SetPolicy sets the redaction policy, it must be called before serving requests.
+func SetPolicy(p Policy) {
+	if p.MaxBodyLength <= 0 {
+		p.MaxBodyLength = defaultMaxBodyLength
+	}
+	policy = p
+}
+
+// WithBodySampling decides whether the request of the user is logged with full bodies:
+// always for debug users, otherwise for the sampled share of requests.
+func WithBodySampling(ctx context.Context, userID string) context.Context {
+	verbose := slices.Contains(policy.DebugUsers, userID) || rand.Float64() < policy.BodySampleRate
+	return context.WithValue(ctx, verboseKey{}, verbose)
+}
+
+// Verbose reports whether full bodies are logged for the request.
+func Verbose(ctx context.Context) bool {
+	verbose, _ := ctx.Value(verboseKey{}).(bool)
+	return verbose
+}
+
+// Body prepares a request or response body for logging: personal data is masked
+// and, unless the request is verbose, the body is truncated.
+func Body(ctx context.Context, body string) string {
+	body = MaskPII(body)
+	if Verbose(ctx) {
+		return body
+	}
+	return truncate(body, policy.MaxBodyLength)
+}
+
+// MaskPII replaces email addresses and phone numbers.
+func MaskPII(s string) string {
+	s = emailRegex.ReplaceAllString(s, "[email]")
+	return phoneRegex.ReplaceAllString(s, "[phone]")
+}
+
+// UserID returns the user ID as it should appear in logs.
+func UserID(userID string) string {
+	if !policy.HashUserIDs || userID == "" {
+		return userID
+	}
+	sum := sha256.Sum256([]byte(userID))
+	return hex.EncodeToString(sum[:8])
+}
+
+func truncate(s string, maxRunes int) string {
+	runes := []rune(s)
+	if len(runes) <= maxRunes {
+		return s
+	}
+	return fmt.Sprintf("%s... (%d more characters)", string(runes[:maxRunes]), len(runes)-maxRunes)
+}
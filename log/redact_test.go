@@ -0,0 +1,58 @@
+package log
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaskPII(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"email", "write me at john.doe+bets@mail.example.com please", "write me at [email] please"},
+		{"international phone", "call +44 20 7946 0958 now", "call [phone] now"},
+		{"local phone", "my number is (555) 123-4567", "my number is [phone]"},
+		{"date is kept", "match on 2025-05-01 at 20:00", "match on 2025-05-01 at 20:00"},
+		{"score and ids are kept", `{"game_id": 19134567, "score": "2-1"}`, `{"game_id": 19134567, "score": "2-1"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, MaskPII(tt.input))
+		})
+	}
+}
+
+func TestBody(t *testing.T) {
+	defer SetPolicy(Policy{})
+	SetPolicy(Policy{MaxBodyLength: 10, DebugUsers: []string{"debug-user"}})
+
+	body := "contact: a@b.io " + strings.Repeat("x", 20)
+	assert.Equal(t, "contact: [... (27 more characters)", Body(context.Background(), body))
+
+	ctx := WithBodySampling(context.Background(), "debug-user")
+	assert.True(t, Verbose(ctx))
+	assert.Equal(t, "contact: [email] "+strings.Repeat("x", 20), Body(ctx, body))
+
+	assert.False(t, Verbose(WithBodySampling(context.Background(), "other-user")))
+
+	SetPolicy(Policy{BodySampleRate: 1})
+	assert.True(t, Verbose(WithBodySampling(context.Background(), "other-user")))
+}
+
+func TestUserID(t *testing.T) {
+	defer SetPolicy(Policy{})
+
+	assert.Equal(t, "uid", UserID("uid"))
+
+	SetPolicy(Policy{HashUserIDs: true})
+	hashed := UserID("uid")
+	assert.Len(t, hashed, 16)
+	assert.NotEqual(t, "uid", hashed)
+	assert.Equal(t, hashed, UserID("uid"))
+	assert.Empty(t, UserID(""))
+}
@@ -0,0 +1,87 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	cloudTraceHeader  = "X-Cloud-Trace-Context"
+	traceparentHeader = "traceparent"
+)
+
+var (
+	// TRACE_ID/SPAN_ID;o=OPTIONS, the span ID is decimal, see https://cloud.google.com/trace/docs/trace-context
+	cloudTraceRegex = regexp.MustCompile(`^([0-9a-fA-F]{32})(?:/(\d+))?(?:;o=(\d))?$`)
+	// VERSION-TRACE_ID-SPAN_ID-FLAGS, see https://www.w3.org/TR/trace-context/#traceparent-header
+	traceparentRegex = regexp.MustCompile(`^([0-9a-f]{2})-([0-9a-f]{32})-([0-9a-f]{16})-([0-9a-f]{2})$`)
+)
+
+type traceKey struct{}
+
+// Trace identifies the request trace log entries are correlated with.
+type Trace struct {
+	ProjectID string
+	TraceID   string // 32 hex characters
+	SpanID    string // 16 hex characters, empty when unknown
+	Sampled   bool
+}
+
+// ParseTrace extracts the trace from the traceparent header, falling back to X-Cloud-Trace-Context.
+func ParseTrace(h http.Header) (Trace, bool) {
+	if m := traceparentRegex.FindStringSubmatch(h.Get(traceparentHeader)); m != nil && m[1] != "ff" && !allZeros(m[2]) && !allZeros(m[3]) {
+		flags, _ := strconv.ParseUint(m[4], 16, 8)
+		return Trace{TraceID: m[2], SpanID: m[3], Sampled: flags&1 == 1}, true
+	}
+	if m := cloudTraceRegex.FindStringSubmatch(h.Get(cloudTraceHeader)); m != nil && !allZeros(m[1]) {
+		t := Trace{TraceID: strings.ToLower(m[1]), Sampled: m[3] == "1"}
+		if spanID, err := strconv.ParseUint(m[2], 10, 64); err == nil && spanID != 0 {
+			t.SpanID = fmt.Sprintf("%016x", spanID)
+		}
+		return t, true
+	}
+	return Trace{}, false
+}
+
+// WithTrace stores the trace in the context and binds the context logger to it,
+// so every entry logged during the request is correlated with the trace.
+func WithTrace(ctx context.Context, t Trace) context.Context {
+	ctx = context.WithValue(ctx, traceKey{}, t)
+	if h, ok := LoggerFromContext(ctx).Handler().(*CloudLoggingHandler); ok {
+		bound := *h
+		bound.trace = &t
+		ctx = WithLogger(ctx, slog.New(&bound))
+	}
+	return ctx
+}
+
+// TraceFromContext returns the trace stored by WithTrace.
+func TraceFromContext(ctx context.Context) (Trace, bool) {
+	t, ok := ctx.Value(traceKey{}).(Trace)
+	return t, ok
+}
+
+// Name is the trace resource name expected by Cloud Logging, projects/PROJECT/traces/ID.
+func (t Trace) Name() string {
+	if t.ProjectID == "" {
+		return t.TraceID
+	}
+	return "projects/" + t.ProjectID + "/traces/" + t.TraceID
+}
+
+func (t Trace) addTo(entry map[string]any) {
+	entry[traceField] = t.Name()
+	if t.SpanID != "" {
+		entry[spanIDField] = t.SpanID
+	}
+	entry[traceSampledField] = t.Sampled
+}
+
+func allZeros(s string) bool {
+	return strings.Trim(s, "0") == ""
+}
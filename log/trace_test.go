@@ -0,0 +1,78 @@
+package log
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTrace(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected Trace
+		ok       bool
+	}{
+		{
+			name:     "traceparent",
+			headers:  map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			expected: Trace{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true},
+			ok:       true,
+		},
+		{
+			name:     "traceparent not sampled",
+			headers:  map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-00"},
+			expected: Trace{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7"},
+			ok:       true,
+		},
+		{
+			name:     "cloud trace context",
+			headers:  map[string]string{"X-Cloud-Trace-Context": "105445AA7843BC8BF206B12000100000/1;o=1"},
+			expected: Trace{TraceID: "105445aa7843bc8bf206b12000100000", SpanID: "0000000000000001", Sampled: true},
+			ok:       true,
+		},
+		{
+			name:     "cloud trace context without span",
+			headers:  map[string]string{"X-Cloud-Trace-Context": "105445aa7843bc8bf206b12000100000"},
+			expected: Trace{TraceID: "105445aa7843bc8bf206b12000100000"},
+			ok:       true,
+		},
+		{
+			name: "traceparent wins",
+			headers: map[string]string{
+				"traceparent":           "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01",
+				"X-Cloud-Trace-Context": "105445aa7843bc8bf206b12000100000/1;o=1",
+			},
+			expected: Trace{TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true},
+			ok:       true,
+		},
+		{
+			name: "invalid traceparent falls back",
+			headers: map[string]string{
+				"traceparent":           "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+				"X-Cloud-Trace-Context": "105445aa7843bc8bf206b12000100000",
+			},
+			expected: Trace{TraceID: "105445aa7843bc8bf206b12000100000"},
+			ok:       true,
+		},
+		{name: "no headers"},
+		{name: "malformed", headers: map[string]string{"X-Cloud-Trace-Context": "abc/1;o=1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := http.Header{}
+			for k, v := range tt.headers {
+				h.Set(k, v)
+			}
+			trace, ok := ParseTrace(h)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, trace)
+		})
+	}
+}
+
+func TestTraceName(t *testing.T) {
+	assert.Equal(t, "projects/p/traces/abc", Trace{ProjectID: "p", TraceID: "abc"}.Name())
+	assert.Equal(t, "abc", Trace{TraceID: "abc"}.Name())
+}
@@ -0,0 +1,39 @@
+package notify
+
+import (
+	"context"
+	"time"
+
+	"github.com/klipach/matchguru/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// claimsCollection holds a document per notification sent, so overlapping or retried job runs don't notify twice
+const claimsCollection = "notifications"
+
+// Claim reserves the notification key, it reports false when the key was claimed before.
+func Claim(ctx context.Context, key string) (bool, error) {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return false, err
+	}
+	_, err = client.Collection(claimsCollection).Doc(key).Create(ctx, map[string]any{"created_at": time.Now().UTC()})
+	if status.Code(err) == codes.AlreadyExists {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Release drops a claim, so a later run can send the notification the claim was taken for.
+func Release(ctx context.Context, key string) error {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = client.Collection(claimsCollection).Doc(key).Delete(ctx)
+	return err
+}
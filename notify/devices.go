@@ -0,0 +1,114 @@
+package notify
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/store"
+)
+
+const (
+	firestoreUserCollection = "users"
+	// DevicesCollection is the per user subcollection of FCM tokens
+	DevicesCollection = "devices"
+)
+
+// Devices stores the FCM tokens of the users' app installs.
+type Devices interface {
+	Tokens(ctx context.Context, userID string) ([]string, error)
+	Remove(ctx context.Context, userID string, tokens []string) error
+}
+
+type device struct {
+	Token     string    `firestore:"token"`
+	UpdatedAt time.Time `firestore:"updated_at"`
+}
+
+// FirestoreDevices keeps the tokens in users/{userID}/devices, one document per token.
+type FirestoreDevices struct{}
+
+// Register stores the token, registering it again refreshes its date.
+func (FirestoreDevices) Register(ctx context.Context, userID, token string) error {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = client.Collection(firestoreUserCollection).Doc(userID).Collection(DevicesCollection).Doc(deviceID(token)).
+		Set(ctx, device{Token: token, UpdatedAt: time.Now().UTC()})
+	return err
+}
+
+func (FirestoreDevices) Tokens(ctx context.Context, userID string) ([]string, error) {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	docs, err := client.Collection(firestoreUserCollection).Doc(userID).Collection(DevicesCollection).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, err
+	}
+	tokens := make([]string, 0, len(docs))
+	for _, doc := range docs {
+		var d device
+		if err := doc.DataTo(&d); err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, d.Token)
+	}
+	return tokens, nil
+}
+
+func (FirestoreDevices) Remove(ctx context.Context, userID string, tokens []string) error {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return err
+	}
+	var errs []error
+	for _, token := range tokens {
+		_, err := client.Collection(firestoreUserCollection).Doc(userID).Collection(DevicesCollection).Doc(deviceID(token)).Delete(ctx)
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// deviceID keeps the tokens, which may contain any character, out of document IDs.
+func deviceID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:16])
+}
+
+// Deliver sends the notification to all devices of the users and forgets the invalid tokens.
+// A failure for one user doesn't stop the others, it returns the number of users notified.
+func Deliver(ctx context.Context, sender Sender, devices Devices, userIDs []string, n Notification) int {
+	logger := log.LoggerFromContext(ctx)
+	notified := 0
+	for _, userID := range userIDs {
+		tokens, err := devices.Tokens(ctx, userID)
+		if err != nil {
+			logger.Error("error while loading devices", slog.String("userID", log.UserID(userID)), slog.String("errorMsg", err.Error()))
+			continue
+		}
+		if len(tokens) == 0 {
+			continue
+		}
+		invalid, err := sender.Send(ctx, tokens, n)
+		if len(invalid) > 0 {
+			if err := devices.Remove(ctx, userID, invalid); err != nil {
+				logger.Error("error while removing invalid devices", slog.String("userID", log.UserID(userID)), slog.String("errorMsg", err.Error()))
+			}
+		}
+		if err != nil {
+			logger.Error("error while sending notification", slog.String("userID", log.UserID(userID)), slog.String("errorMsg", err.Error()))
+			continue
+		}
+		if len(invalid) < len(tokens) {
+			notified++
+		}
+	}
+	return notified
+}
@@ -0,0 +1,133 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	firebase "firebase.google.com/go/v4"
+	"firebase.google.com/go/v4/messaging"
+	"github.com/klipach/matchguru/log"
+)
+
+// senders selected by NOTIFY_SENDER
+const (
+	SenderFCM = "fcm"
+	// SenderLog logs the notifications instead of sending them, for local runs against the Firestore emulator,
+	// FCM has no emulator
+	SenderLog = "log"
+)
+
+// Notification is a push notification shown on the user's devices.
+type Notification struct {
+	Title string
+	Body  string
+	// Data is handed to the app when the notification is opened, such as the game ID
+	Data map[string]string
+}
+
+// Sender delivers a notification to device tokens.
+type Sender interface {
+	// Send returns the tokens which are no longer valid, such as of uninstalled apps,
+	// the caller should forget them. err is set when nothing could be sent.
+	Send(ctx context.Context, tokens []string, n Notification) (invalid []string, err error)
+}
+
+// NewSender returns the sender selected by name.
+func NewSender(ctx context.Context, name string) (Sender, error) {
+	switch name {
+	case SenderFCM:
+		app, err := firebase.NewApp(context.WithoutCancel(ctx), nil)
+		if err != nil {
+			return nil, err
+		}
+		client, err := app.Messaging(context.WithoutCancel(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return &FCMSender{client: client}, nil
+	case SenderLog:
+		return LogSender{}, nil
+	default:
+		return nil, fmt.Errorf("unknown sender %q", name)
+	}
+}
+
+// FCMSender sends through Firebase Cloud Messaging.
+type FCMSender struct {
+	client *messaging.Client
+}
+
+func (s *FCMSender) Send(ctx context.Context, tokens []string, n Notification) ([]string, error) {
+	if len(tokens) == 0 {
+		return nil, nil
+	}
+	resp, err := s.client.SendEachForMulticast(ctx, &messaging.MulticastMessage{
+		Tokens:       tokens,
+		Notification: &messaging.Notification{Title: n.Title, Body: n.Body},
+		Data:         n.Data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var invalid []string
+	var errs []error
+	for i, r := range resp.Responses {
+		switch {
+		case r.Success:
+		case messaging.IsRegistrationTokenNotRegistered(r.Error) || messaging.IsInvalidArgument(r.Error):
+			invalid = append(invalid, tokens[i])
+		default:
+			errs = append(errs, r.Error)
+		}
+	}
+	if resp.SuccessCount == 0 && len(errs) > 0 {
+		return invalid, errors.Join(errs...)
+	}
+	return invalid, nil
+}
+
+// LogSender logs the notifications instead of sending them.
+type LogSender struct{}
+
+func (LogSender) Send(ctx context.Context, tokens []string, n Notification) ([]string, error) {
+	log.LoggerFromContext(ctx).Info("notification",
+		slog.Int("devices", len(tokens)),
+		slog.String("title", n.Title),
+		slog.String("body", log.Body(ctx, n.Body)),
+		slog.Any("data", n.Data),
+	)
+	return nil, nil
+}
+
+// Fake records the sent notifications, tokens in Invalid are reported invalid.
+type Fake struct {
+	mu      sync.Mutex
+	Invalid map[string]bool
+	Sent    []Sent
+}
+
+// Sent is a notification recorded by Fake.
+type Sent struct {
+	Tokens       []string
+	Notification Notification
+}
+
+func (f *Fake) Send(_ context.Context, tokens []string, n Notification) ([]string, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	var valid, invalid []string
+	for _, t := range tokens {
+		if f.Invalid[t] {
+			invalid = append(invalid, t)
+		} else {
+			valid = append(valid, t)
+		}
+	}
+	if len(valid) > 0 {
+		f.Sent = append(f.Sent, Sent{Tokens: valid, Notification: n})
+	}
+	return invalid, nil
+}
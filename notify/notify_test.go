@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDevices struct {
+	tokens  map[string][]string
+	removed map[string][]string
+}
+
+func (d *fakeDevices) Tokens(_ context.Context, userID string) ([]string, error) {
+	if userID == "broken" {
+		return nil, errors.New("unavailable")
+	}
+	return d.tokens[userID], nil
+}
+
+func (d *fakeDevices) Remove(_ context.Context, userID string, tokens []string) error {
+	d.removed[userID] = append(d.removed[userID], tokens...)
+	return nil
+}
+
+func TestDeliver(t *testing.T) {
+	devices := &fakeDevices{
+		tokens: map[string][]string{
+			"uid1": {"phone", "tablet"},
+			"uid2": {"uninstalled"},
+		},
+		removed: map[string][]string{},
+	}
+	sender := &Fake{Invalid: map[string]bool{"uninstalled": true}}
+	n := Notification{Title: "Arsenal vs Chelsea", Body: "Preview", Data: map[string]string{"game_id": "1"}}
+
+	notified := Deliver(context.Background(), sender, devices, []string{"uid1", "uid2", "uid3", "broken"}, n)
+	assert.Equal(t, 1, notified)
+	require.Len(t, sender.Sent, 1)
+	assert.Equal(t, Sent{Tokens: []string{"phone", "tablet"}, Notification: n}, sender.Sent[0])
+	assert.Equal(t, map[string][]string{"uid2": {"uninstalled"}}, devices.removed)
+}
+
+func TestDeviceID(t *testing.T) {
+	assert.Equal(t, deviceID("token"), deviceID("token"))
+	assert.NotEqual(t, deviceID("token"), deviceID("other"))
+	assert.Len(t, deviceID("a:b/c"), 32)
+}
@@ -0,0 +1,86 @@
+package matchguru
+
+import (
+	"context"
+	"log/slog"
+
+	"github.com/klipach/matchguru/config"
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/fixture"
+	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/odds"
+	"github.com/klipach/matchguru/preferences"
+	"github.com/klipach/matchguru/sport"
+	"github.com/klipach/matchguru/telemetry"
+)
+
+// notStartedState is the SportMonks state of a game before kick-off, the only one with pre-match odds
+const notStartedState = "NS"
+
+// fetchOdds adds the pre-match odds to a football fixture not started yet, for plans with betting analysis.
+// Failures and games without odds leave the fixture as is, the answer is only less grounded.
+func (b *bot) fetchOdds(ctx context.Context, sp sport.Sport, f *fixture.Fixture) {
+	if b.odds == nil || sp != sport.Football || f.State != notStartedState {
+		return
+	}
+	logger := log.LoggerFromContext(ctx)
+	ctx, span := tracer.Start(ctx, "bot.odds")
+	ctx, cancel := context.WithTimeout(ctx, b.cfg.FixtureFetchTimeout.Duration)
+	defer cancel()
+
+	o, err := b.odds.FetchOdds(ctx, f.ID)
+	endSpan(span, err)
+	if err != nil {
+		telemetry.RecordFixtureError(ctx)
+		logger.Error("error while fetching odds", slog.Int(gameIDLogField, f.ID), slog.String(ErrorMsgLogField, err.Error()))
+		return
+	}
+	if o == nil {
+		logger.Info("no odds for fixture", slog.Int(gameIDLogField, f.ID))
+		return
+	}
+	f.Odds = o
+	logger.Info("odds fetched", slog.Int("bookmakers", o.Bookmakers))
+}
+
+// oddsFetcher returns the configured odds source, nil when odds are disabled.
+func oddsFetcher(source string, football *fixture.Client) fixture.OddsFetcher {
+	switch source {
+	case config.OddsSourceSportmonks:
+		return football
+	case config.OddsSourceFake:
+		return fixture.FakeOdds{}
+	default:
+		return nil
+	}
+}
+
+// oddsEvent converts the odds to the format of the user's preferences, decimal by default,
+// with the implied probabilities of the average prices.
+func oddsEvent(gameID int, o *fixture.Odds, prefs *preferences.Preferences) *contract.Odds {
+	format := odds.Decimal
+	if prefs != nil && prefs.OddsFormat != "" {
+		format = prefs.OddsFormat
+	}
+	decimals := o.Decimals()
+	fair := odds.FairProbabilities(decimals)
+	event := &contract.Odds{
+		GameID:     gameID,
+		Market:     o.Market,
+		Format:     format,
+		Bookmakers: o.Bookmakers,
+		Margin:     odds.Margin(decimals),
+	}
+	for i, outcome := range o.Outcomes {
+		event.Outcomes = append(event.Outcomes, contract.OddsOutcome{
+			Label:              outcome.Label,
+			Price:              odds.Format(outcome.Average, format),
+			Decimal:            outcome.Average,
+			ImpliedProbability: odds.ImpliedProbability(outcome.Average),
+			FairProbability:    fair[i],
+			BestPrice:          odds.Format(outcome.Best, format),
+			BestBookmaker:      outcome.BestBookmaker,
+		})
+	}
+	return event
+}
@@ -0,0 +1,97 @@
+// Package odds converts decimal odds to the formats users read them in and to probabilities.
+package odds
+
+import (
+	"math"
+	"strconv"
+)
+
+// formats odds are shown in
+const (
+	Decimal    = "decimal"    // 2.50, the stake included in the return
+	Fractional = "fractional" // 3/2, the profit per stake, common in the UK
+	American   = "american"   // +150 or -200, the profit per 100 staked or the stake to win 100
+)
+
+// maxDenominator keeps fractional odds readable, bookmakers quote 10/11 rather than 91/100
+const maxDenominator = 20
+
+// Format returns the decimal odds in the format, decimal for unknown formats.
+// Odds of 1 or less don't pay and are returned as is.
+func Format(decimal float64, format string) string {
+	if decimal <= 1 {
+		return strconv.FormatFloat(decimal, 'f', 2, 64)
+	}
+	switch format {
+	case Fractional:
+		return ToFractional(decimal)
+	case American:
+		return ToAmerican(decimal)
+	default:
+		return strconv.FormatFloat(decimal, 'f', 2, 64)
+	}
+}
+
+// ToFractional returns the closest fraction with a denominator up to 20, such as 11/10 for 2.10.
+func ToFractional(decimal float64) string {
+	profit := decimal - 1
+	bestNum, bestDen := 0, 1
+	bestErr := math.Inf(1)
+	for den := 1; den <= maxDenominator; den++ {
+		num := int(math.Round(profit * float64(den)))
+		if num == 0 {
+			continue
+		}
+		// the smallest denominator wins ties
+		if err := math.Abs(profit - float64(num)/float64(den)); err < bestErr-1e-9 {
+			bestNum, bestDen, bestErr = num, den, err
+		}
+	}
+	if bestNum == 0 {
+		// shorter than 1/20
+		bestNum, bestDen = 1, int(math.Round(1/profit))
+	}
+	return strconv.Itoa(bestNum) + "/" + strconv.Itoa(bestDen)
+}
+
+// ToAmerican returns the moneyline odds, +110 for 2.10 and -200 for 1.50.
+func ToAmerican(decimal float64) string {
+	if decimal >= 2 {
+		return "+" + strconv.Itoa(int(math.Round((decimal-1)*100)))
+	}
+	return strconv.Itoa(-int(math.Round(100 / (decimal - 1))))
+}
+
+// ImpliedProbability is the probability the odds stand for, margin of the bookmaker included.
+func ImpliedProbability(decimal float64) float64 {
+	if decimal <= 0 {
+		return 0
+	}
+	return 1 / decimal
+}
+
+// Margin is the bookmaker's margin of a market, the amount the implied probabilities of all
+// outcomes add up to over 1, such as 0.05 for 5%.
+func Margin(decimals []float64) float64 {
+	total := 0.0
+	for _, d := range decimals {
+		total += ImpliedProbability(d)
+	}
+	if total == 0 {
+		return 0
+	}
+	return total - 1
+}
+
+// FairProbabilities are the implied probabilities of all outcomes of a market with the margin removed,
+// so they add up to 1.
+func FairProbabilities(decimals []float64) []float64 {
+	total := 1 + Margin(decimals)
+	probabilities := make([]float64, len(decimals))
+	for i, d := range decimals {
+		if total > 0 {
+			probabilities[i] = ImpliedProbability(d) / total
+		}
+	}
+	return probabilities
+}
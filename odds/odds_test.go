@@ -0,0 +1,48 @@
+package odds
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormat(t *testing.T) {
+	tests := []struct {
+		decimal  float64
+		format   string
+		expected string
+	}{
+		{2.10, Decimal, "2.10"},
+		{2.10, Fractional, "11/10"},
+		{2.10, American, "+110"},
+		{2.00, Fractional, "1/1"},
+		{2.00, American, "+100"},
+		{1.50, Fractional, "1/2"},
+		{1.50, American, "-200"},
+		{1.91, Fractional, "10/11"},
+		{1.91, American, "-110"},
+		{3.40, Fractional, "12/5"},
+		{26.0, Fractional, "25/1"},
+		{1.02, Fractional, "1/50"},
+		{1.25, "", "1.25"},
+		{1.00, American, "1.00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			assert.Equal(t, tt.expected, Format(tt.decimal, tt.format))
+		})
+	}
+}
+
+func TestProbabilities(t *testing.T) {
+	assert.InDelta(t, 0.5, ImpliedProbability(2), 1e-9)
+	assert.Zero(t, ImpliedProbability(0))
+
+	market := []float64{2.10, 3.40, 3.60}
+	assert.InDelta(t, 0.0481, Margin(market), 1e-4)
+
+	fair := FairProbabilities(market)
+	assert.InDelta(t, 1, fair[0]+fair[1]+fair[2], 1e-9)
+	assert.InDelta(t, 0.4543, fair[0], 1e-4)
+	assert.Greater(t, fair[1], fair[2])
+}
@@ -0,0 +1,74 @@
+package plan
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/klipach/matchguru/ratelimit"
+)
+
+const (
+	// Claim is the Firebase custom claim holding the user's subscription plan
+	Claim = "plan"
+
+	Free = "free"
+	Pro  = "pro"
+)
+
+// Entitlements are what a subscription plan gives access to.
+type Entitlements struct {
+	Plan            string           `json:"-"`                // the name the plan is configured under
+	Model           string           `json:"model"`            // OpenAI model answering the user
+	MaxTokens       int              `json:"max_tokens"`       // max tokens of a single answer
+	HistoryDepth    int              `json:"history_depth"`    // number of last chat messages sent to the model, 0 means the whole chat
+	BettingAnalysis bool             `json:"betting_analysis"` // whether the bot gives betting advice
+	RateLimits      ratelimit.Limits `json:"rate_limits"`
+}
+
+// Plans are the entitlements by plan name, loaded with the config.
+type Plans map[string]Entitlements
+
+// Defaults returns the plans used when the config sets none.
+func Defaults() Plans {
+	return Plans{
+		Free: {
+			Plan:            Free,
+			Model:           "gpt-4o-mini-search-preview",
+			MaxTokens:       1000,
+			HistoryDepth:    20,
+			BettingAnalysis: false,
+			RateLimits:      ratelimit.Limits{PerMinute: 5, DailyMessages: 50, DailyTokens: 100_000},
+		},
+		Pro: {
+			Plan:            Pro,
+			Model:           "gpt-4o-search-preview",
+			MaxTokens:       2000,
+			HistoryDepth:    0,
+			BettingAnalysis: true,
+			RateLimits:      ratelimit.Limits{PerMinute: 20, DailyMessages: 500, DailyTokens: 2_000_000},
+		},
+	}
+}
+
+// FromClaims returns the entitlements of the plan set in the token custom claims,
+// users without a known plan get the free plan.
+func (p Plans) FromClaims(claims map[string]any) Entitlements {
+	name, _ := claims[Claim].(string)
+	e, ok := p[name]
+	if !ok {
+		name, e = Free, p[Free]
+	}
+	e.Plan = name
+	return e
+}
+
+// Names returns the names of all known plans.
+func (p Plans) Names() []string {
+	return slices.Sorted(maps.Keys(p))
+}
+
+// Exists reports whether name is a known plan.
+func (p Plans) Exists(name string) bool {
+	_, ok := p[name]
+	return ok
+}
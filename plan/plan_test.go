@@ -0,0 +1,43 @@
+package plan
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromClaims(t *testing.T) {
+	tests := []struct {
+		name     string
+		claims   map[string]any
+		expected string
+	}{
+		{name: "pro plan", claims: map[string]any{Claim: Pro}, expected: Pro},
+		{name: "free plan", claims: map[string]any{Claim: Free}, expected: Free},
+		{name: "unknown plan", claims: map[string]any{Claim: "gold"}, expected: Free},
+		{name: "plan is not a string", claims: map[string]any{Claim: 1}, expected: Free},
+		{name: "no claims", claims: nil, expected: Free},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := Defaults().FromClaims(tt.claims)
+			assert.Equal(t, tt.expected, e.Plan)
+			assert.Equal(t, Defaults()[tt.expected], e)
+		})
+	}
+}
+
+func TestFromClaimsConfigured(t *testing.T) {
+	// plans loaded from the config carry no name, it is the key they are configured under
+	plans := Plans{
+		Free:   {Model: "small"},
+		"gold": {Model: "large", BettingAnalysis: true},
+	}
+
+	e := plans.FromClaims(map[string]any{Claim: "gold"})
+	assert.Equal(t, Entitlements{Plan: "gold", Model: "large", BettingAnalysis: true}, e)
+
+	e = plans.FromClaims(map[string]any{Claim: Pro})
+	assert.Equal(t, Entitlements{Plan: Free, Model: "small"}, e)
+}
@@ -0,0 +1,44 @@
+package matchguru
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	fbauth "firebase.google.com/go/v4/auth"
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/preferences"
+)
+
+func getPreferences(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	p, err := preferences.Load(r.Context(), token.UID)
+	if err != nil {
+		log.LoggerFromContext(r.Context()).Error("error while loading preferences", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, p.Contract())
+}
+
+// putPreferences replaces all preferences, fields left out are unset.
+func putPreferences(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	logger := log.LoggerFromContext(r.Context())
+	var req contract.Preferences
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	p, err := preferences.FromContract(req)
+	if err != nil {
+		logger.Warn("invalid preferences", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+	if err := preferences.Save(r.Context(), token.UID, p); err != nil {
+		logger.Error("error while saving preferences", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, p.Contract())
+}
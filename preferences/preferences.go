@@ -0,0 +1,166 @@
+package preferences
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"cloud.google.com/go/firestore"
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/odds"
+	"github.com/klipach/matchguru/store"
+	"golang.org/x/text/language"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	firestoreUserCollection = "users"
+	// preferences are a field of the user document, next to the chats written by the client
+	preferencesField = "preferences"
+
+	maxFavouriteTeams = 10
+	maxTeamNameLength = 100
+)
+
+const (
+	RiskLow    = "low"
+	RiskMedium = "medium"
+	RiskHigh   = "high"
+
+	OddsDecimal    = odds.Decimal
+	OddsFractional = odds.Fractional
+	OddsAmerican   = odds.American
+
+	VerbosityBrief    = "brief"
+	VerbosityNormal   = "normal"
+	VerbosityDetailed = "detailed"
+
+	UnitsMetric   = "metric"
+	UnitsImperial = "imperial"
+)
+
+var (
+	ErrInvalid = errors.New("invalid preferences")
+
+	riskTolerances = []string{RiskLow, RiskMedium, RiskHigh}
+	oddsFormats    = []string{OddsDecimal, OddsFractional, OddsAmerican}
+	verbosities    = []string{VerbosityBrief, VerbosityNormal, VerbosityDetailed}
+	units          = []string{UnitsMetric, UnitsImperial}
+)
+
+// Preferences are the user's preferences as stored and rendered into the system prompt.
+type Preferences struct {
+	FavouriteTeams []string `firestore:"favourite_teams,omitempty"`
+	Language       string   `firestore:"language,omitempty"`
+	RiskTolerance  string   `firestore:"risk_tolerance,omitempty"`
+	OddsFormat     string   `firestore:"odds_format,omitempty"`
+	Verbosity      string   `firestore:"verbosity,omitempty"`
+	Units          string   `firestore:"units,omitempty"`
+}
+
+// IsZero reports whether no preference is set.
+func (p Preferences) IsZero() bool {
+	return len(p.FavouriteTeams) == 0 && p.Language == "" && p.RiskTolerance == "" &&
+		p.OddsFormat == "" && p.Verbosity == "" && p.Units == ""
+}
+
+// FromContract validates and normalizes the preferences of a request,
+// enum values are lowercased and team names trimmed and deduplicated.
+func FromContract(req contract.Preferences) (Preferences, error) {
+	p := Preferences{
+		RiskTolerance: strings.ToLower(strings.TrimSpace(req.RiskTolerance)),
+		OddsFormat:    strings.ToLower(strings.TrimSpace(req.OddsFormat)),
+		Verbosity:     strings.ToLower(strings.TrimSpace(req.Verbosity)),
+		Units:         strings.ToLower(strings.TrimSpace(req.Units)),
+	}
+	var errs []error
+	for _, name := range req.FavouriteTeams {
+		name = strings.Join(strings.Fields(name), " ")
+		switch {
+		case name == "":
+		case utf8.RuneCountInString(name) > maxTeamNameLength:
+			errs = append(errs, fmt.Errorf("favourite team %q is too long", name))
+		case !slices.ContainsFunc(p.FavouriteTeams, func(t string) bool { return strings.EqualFold(t, name) }):
+			p.FavouriteTeams = append(p.FavouriteTeams, name)
+		}
+	}
+	if len(p.FavouriteTeams) > maxFavouriteTeams {
+		errs = append(errs, fmt.Errorf("at most %d favourite teams", maxFavouriteTeams))
+	}
+	if v := strings.TrimSpace(req.Language); v != "" {
+		tag, err := language.Parse(v)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("invalid language %q", v))
+		} else {
+			p.Language = tag.String()
+		}
+	}
+	for _, field := range []struct {
+		name, value string
+		allowed     []string
+	}{
+		{"risk_tolerance", p.RiskTolerance, riskTolerances},
+		{"odds_format", p.OddsFormat, oddsFormats},
+		{"verbosity", p.Verbosity, verbosities},
+		{"units", p.Units, units},
+	} {
+		if field.value != "" && !slices.Contains(field.allowed, field.value) {
+			errs = append(errs, fmt.Errorf("invalid %s %q", field.name, field.value))
+		}
+	}
+	if err := errors.Join(errs...); err != nil {
+		return Preferences{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	return p, nil
+}
+
+// Contract returns the preferences as sent to the client.
+func (p Preferences) Contract() contract.Preferences {
+	return contract.Preferences{
+		FavouriteTeams: p.FavouriteTeams,
+		Language:       p.Language,
+		RiskTolerance:  p.RiskTolerance,
+		OddsFormat:     p.OddsFormat,
+		Verbosity:      p.Verbosity,
+		Units:          p.Units,
+	}
+}
+
+type firestoreUser struct {
+	Preferences Preferences `firestore:"preferences"`
+}
+
+// Load reads the user's preferences, a user without preferences gets zero preferences.
+func Load(ctx context.Context, userID string) (Preferences, error) {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return Preferences{}, err
+	}
+	doc, err := client.Collection(firestoreUserCollection).Doc(userID).Get(ctx)
+	if status.Code(err) == codes.NotFound {
+		return Preferences{}, nil
+	}
+	if err != nil {
+		return Preferences{}, err
+	}
+	var user firestoreUser
+	if err := doc.DataTo(&user); err != nil {
+		return Preferences{}, err
+	}
+	return user.Preferences, nil
+}
+
+// Save replaces the user's preferences, leaving the rest of the user document as is.
+func Save(ctx context.Context, userID string, p Preferences) error {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = client.Collection(firestoreUserCollection).Doc(userID).
+		Set(ctx, map[string]any{preferencesField: p}, firestore.Merge([]string{preferencesField}))
+	return err
+}
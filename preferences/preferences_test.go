@@ -0,0 +1,72 @@
+package preferences
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/klipach/matchguru/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromContract(t *testing.T) {
+	tests := []struct {
+		name     string
+		req      contract.Preferences
+		expected Preferences
+		err      string
+	}{
+		{
+			name:     "empty",
+			req:      contract.Preferences{},
+			expected: Preferences{},
+		},
+		{
+			name: "normalized",
+			req: contract.Preferences{
+				FavouriteTeams: []string{" Arsenal ", "arsenal", "", "Real  Madrid"},
+				Language:       "pt-br",
+				RiskTolerance:  "Low",
+				OddsFormat:     " american",
+				Verbosity:      "brief",
+				Units:          "IMPERIAL",
+			},
+			expected: Preferences{
+				FavouriteTeams: []string{"Arsenal", "Real Madrid"},
+				Language:       "pt-BR",
+				RiskTolerance:  RiskLow,
+				OddsFormat:     OddsAmerican,
+				Verbosity:      VerbosityBrief,
+				Units:          UnitsImperial,
+			},
+		},
+		{
+			name: "invalid values",
+			req:  contract.Preferences{Language: "not a language", RiskTolerance: "yolo", Units: "furlongs"},
+			err:  `invalid language "not a language"`,
+		},
+		{
+			name: "too many teams",
+			req:  contract.Preferences{FavouriteTeams: strings.Split("a,b,c,d,e,f,g,h,i,j,k", ",")},
+			err:  "at most 10 favourite teams",
+		},
+		{
+			name: "team name too long",
+			req:  contract.Preferences{FavouriteTeams: []string{strings.Repeat("a", 101)}},
+			err:  "is too long",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := FromContract(tt.req)
+			if tt.err != "" {
+				require.ErrorIs(t, err, ErrInvalid)
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, p)
+			assert.Equal(t, tt.expected.IsZero(), tt.name == "empty")
+		})
+	}
+}
@@ -0,0 +1,208 @@
+package matchguru
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/klipach/matchguru/config"
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/filter"
+	"github.com/klipach/matchguru/fixture"
+	"github.com/klipach/matchguru/follow"
+	"github.com/klipach/matchguru/locale"
+	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/notify"
+	"github.com/klipach/matchguru/sport"
+	"github.com/klipach/matchguru/store"
+	"github.com/klipach/matchguru/telemetry"
+	"github.com/klipach/matchguru/usage"
+	"github.com/tmc/langchaingo/llms"
+)
+
+const (
+	previewsFunctionTarget = "Previews"
+
+	previewGenerationTimeout = 30 * time.Second
+	previewMaxTokens         = 250
+	previewInstruction       = "Write a preview of this game for a push notification sent before kick-off. " +
+		"At most 3 short sentences, plain text without links, markdown or emojis."
+)
+
+var errNoPreview = errors.New("no preview generated")
+
+// previewJob sends a preview of the upcoming fixtures of followed teams and leagues to their followers.
+// It is run by Cloud Scheduler more often than the lead time, fixtures already previewed are skipped.
+type previewJob struct {
+	bot      *bot
+	upcoming fixture.UpcomingFetcher
+	sender   notify.Sender
+	devices  notify.Devices
+}
+
+// NewPreviewsHandler serves the preview job, a POST runs it once.
+// The function is deployed without public access and invoked by Cloud Scheduler.
+func NewPreviewsHandler(ctx context.Context, cfg *config.Config) (http.Handler, error) {
+	log.SetLevel(cfg.LogLevel)
+	log.SetPolicy(cfg.Log)
+	usage.SetPrices(cfg.OpenAIPrices)
+	store.SetProjectID(cfg.ProjectID)
+
+	b, err := newBot(cfg)
+	if err != nil {
+		return nil, err
+	}
+	sender, err := notify.NewSender(ctx, cfg.NotifySender)
+	if err != nil {
+		return nil, err
+	}
+	j := &previewJob{
+		bot:      b,
+		upcoming: fixture.NewClient(cfg.SportmonksAPIKey, cfg.SportmonksBaseURL),
+		sender:   sender,
+		devices:  notify.FirestoreDevices{},
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /", j.serveHTTP)
+	return telemetry.Middleware(cfg.ProjectID, recoverPanics(mux)), nil
+}
+
+func (j *previewJob) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	run, err := j.run(r.Context())
+	if err != nil {
+		log.LoggerFromContext(r.Context()).Error("error while running previews", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	writeJSON(w, r, http.StatusOK, run)
+}
+
+func (j *previewJob) run(ctx context.Context) (contract.PreviewRun, error) {
+	logger := log.LoggerFromContext(ctx)
+	var run contract.PreviewRun
+
+	followers, err := follow.Followers(ctx)
+	if err != nil {
+		return run, err
+	}
+	teamIDs, leagueIDs := follow.IDs(followers)
+	if len(teamIDs) == 0 && len(leagueIDs) == 0 {
+		return run, nil
+	}
+	now := time.Now().UTC()
+	fixtures, err := j.upcoming.Upcoming(ctx, now, now.Add(j.bot.cfg.PreviewLeadTime.Duration), teamIDs, leagueIDs)
+	if err != nil {
+		telemetry.RecordFixtureError(ctx)
+		return run, err
+	}
+
+	// generation stops at the budget, below the function timeout, so the run ends before it is killed
+	// and the fixtures left are previewed by the next run
+	deadline := now.Add(j.bot.cfg.PreviewRunBudget.Duration)
+	for _, f := range fixtures {
+		recipients := follow.Recipients(f, followers)
+		if len(recipients) == 0 {
+			continue
+		}
+		run.Fixtures++
+		if time.Now().After(deadline) {
+			run.Deferred++
+			continue
+		}
+		flogger := logger.With(slog.Int(gameIDLogField, f.ID))
+
+		// claimed before generating, so overlapping runs don't send it twice
+		key := "preview_" + strconv.Itoa(f.ID)
+		claimed, err := notify.Claim(ctx, key)
+		if err != nil {
+			flogger.Error("error while claiming preview", slog.String(ErrorMsgLogField, err.Error()))
+			continue
+		}
+		if !claimed {
+			continue
+		}
+		gctx, cancel := context.WithDeadline(log.WithLogger(ctx, flogger), deadline)
+		preview, err := j.generatePreview(gctx, f)
+		cancel()
+		if err != nil {
+			flogger.Error("error while generating preview", slog.String(ErrorMsgLogField, err.Error()))
+			// released, so the next run tries again, also when the generation was cut by the deadline
+			if err := notify.Release(context.WithoutCancel(ctx), key); err != nil {
+				flogger.Error("error while releasing preview", slog.String(ErrorMsgLogField, err.Error()))
+			}
+			continue
+		}
+		run.Previews++
+
+		sent := notify.Deliver(log.WithLogger(ctx, flogger), j.sender, j.devices, recipients, notify.Notification{
+			Title: f.HomeTeam.Name + " vs " + f.AwayTeam.Name,
+			Body:  preview,
+			Data: map[string]string{
+				"game_id": strconv.Itoa(f.ID),
+				"sport":   string(sport.Football),
+			},
+		})
+		flogger.Info("preview sent", slog.Int("followers", len(recipients)), slog.Int("notified", sent))
+		run.Sent += sent
+	}
+	if run.Deferred > 0 {
+		logger.Warn("preview run budget spent", slog.Int("deferred", run.Deferred))
+	}
+	return run, nil
+}
+
+// generatePreview writes the preview with the football system prompt and the fixture, the same way chats about
+// the game are answered, betting analysis excluded as the notification is not bound to a plan.
+func (j *previewJob) generatePreview(ctx context.Context, f *fixture.Fixture) (string, error) {
+	ctx, span := tracer.Start(ctx, "bot.preview")
+	preview, err := j.preview(ctx, f)
+	endSpan(span, err)
+	return preview, err
+}
+
+func (j *previewJob) preview(ctx context.Context, f *fixture.Fixture) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, previewGenerationTimeout)
+	defer cancel()
+
+	var systemPrompt strings.Builder
+	now := time.Now().UTC()
+	if err := j.bot.prompts[sport.Football].template.Execute(&systemPrompt, promptData{
+		UserLocalTime: now.Format(time.RFC1123Z),
+		UserOffset:    now.Format("-07:00"),
+		Fixture:       f,
+		// a preview is shared by the followers, so it is written in the default language
+		Language: locale.Name(locale.Default),
+		Refusals: locale.For(locale.Default).RefusalsFor(sport.Football),
+	}); err != nil {
+		return "", err
+	}
+
+	model := j.bot.cfg.PreviewModel
+	llm, err := j.bot.openAIClient(model)
+	if err != nil {
+		return "", err
+	}
+	resp, err := llm.GenerateContent(ctx,
+		[]llms.MessageContent{
+			llms.TextParts(llms.ChatMessageTypeSystem, systemPrompt.String()),
+			llms.TextParts(llms.ChatMessageTypeHuman, previewInstruction),
+		},
+		llms.WithMaxTokens(previewMaxTokens),
+	)
+	if err != nil {
+		return "", err
+	}
+	// not bound to a user, so the usage is only counted in the metrics
+	entry := usage.NewEntry(model, 0, resp, time.Now())
+	telemetry.RecordTokens(ctx, model, entry.PromptTokens, entry.CompletionTokens)
+	if len(resp.Choices) == 0 || filter.IsRefusal(resp.Choices[0].Content) {
+		return "", errNoPreview
+	}
+	// the prompt asks for internal links, a notification can't open them
+	return strings.TrimSpace(filter.PlainText(resp.Choices[0].Content)), nil
+}
@@ -0,0 +1,93 @@
+package matchguru
+
+import "github.com/klipach/matchguru/sport"
+
+// sportProfile is what the system prompt says about a sport, the prompt template is shared by all sports.
+type sportProfile struct {
+	// Name is the sport as the prompt names it, such as "soccer"
+	Name  string
+	Title string
+	// Domain is the sport as the topic restrictions name it, such as "soccer/football"
+	Domain string
+	// TrustedSites are the websites the model is told to take information from
+	TrustedSites []string
+	// OtherSports are examples of the sports the model declines, the first is used in the example scenarios
+	OtherSports []string
+	// Star is the player of the off-topic example scenario
+	Star string
+	// Teams and Leagues are examples of the name formatting, Translated are Ukrainian display names
+	Teams      []nameExample
+	Leagues    []nameExample
+	Translated []nameExample
+}
+
+// nameExample is a team or league name as the prompt asks to format it, {Display|English}.
+type nameExample struct {
+	Display string
+	English string
+}
+
+var sportProfiles = map[sport.Sport]sportProfile{
+	sport.Football: {
+		Name:         "soccer",
+		Title:        "Soccer",
+		Domain:       "soccer/football",
+		TrustedSites: []string{"ESPN", "SofaScore", "Football365", "Goal.com", "Sky Sports", "official league websites"},
+		OtherSports:  []string{"basketball", "tennis", "cricket"},
+		Star:         "Messi",
+		Teams: []nameExample{
+			{"Arsenal FC", "Arsenal FC"},
+			{"FC Barcelona", "Barcelona"},
+			{"Bayern Munich", "Bayern Munich"},
+			{"Paris Saint-Germain", "Paris Saint-Germain"},
+			{"Manchester United", "Manchester United"},
+			{"Real Madrid CF", "Real Madrid"},
+			{"AC Milan", "AC Milan"},
+			{"Juventus FC", "Juventus"},
+			{"Burnley", "Burnley"},
+			{"AFC Bournemouth", "AFC Bournemouth"},
+		},
+		Leagues: []nameExample{
+			{"Premier League", "Premier League"},
+			{"La Liga", "La Liga"},
+			{"Bundesliga", "Bundesliga"},
+			{"Serie A", "Serie A"},
+			{"Ligue 1", "Ligue 1"},
+			{"UEFA Champions League", "UEFA Champions League"},
+			{"Europa League", "Europa League"},
+		},
+		Translated: []nameExample{
+			{"Бернлі", "Burnley"},
+			{"Борнмут", "AFC Bournemouth"},
+			{"Арсенал", "Arsenal"},
+		},
+	},
+	sport.Cricket: {
+		Name:         "cricket",
+		Title:        "Cricket",
+		Domain:       "cricket",
+		TrustedSites: []string{"ESPNcricinfo", "Cricbuzz", "ICC", "BBC Sport", "official board websites"},
+		OtherSports:  []string{"football", "basketball", "tennis"},
+		Star:         "Virat Kohli",
+		Teams: []nameExample{
+			{"India", "India"},
+			{"Australia", "Australia"},
+			{"England", "England"},
+			{"Mumbai Indians", "Mumbai Indians"},
+			{"Chennai Super Kings", "Chennai Super Kings"},
+			{"Perth Scorchers", "Perth Scorchers"},
+			{"Yorkshire", "Yorkshire"},
+		},
+		Leagues: []nameExample{
+			{"Indian Premier League", "Indian Premier League"},
+			{"Big Bash League", "Big Bash League"},
+			{"County Championship", "County Championship"},
+			{"ICC Cricket World Cup", "ICC Cricket World Cup"},
+			{"The Hundred", "The Hundred"},
+		},
+		Translated: []nameExample{
+			{"Індія", "India"},
+			{"Австралія", "Australia"},
+		},
+	},
+}
@@ -0,0 +1,13 @@
+package ratelimit
+
+// Limits are the quotas a user is allowed, zero value of a field means no limit.
+type Limits struct {
+	PerMinute     int `json:"per_minute"`     // messages per minute, enforced by a token bucket
+	DailyMessages int `json:"daily_messages"` // messages per UTC day
+	DailyTokens   int `json:"daily_tokens"`   // OpenAI tokens per UTC day
+}
+
+func (limits Limits) exceeded(usage Usage) bool {
+	return (limits.DailyMessages > 0 && usage.Messages >= limits.DailyMessages) ||
+		(limits.DailyTokens > 0 && usage.Tokens >= limits.DailyTokens)
+}
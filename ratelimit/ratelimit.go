@@ -0,0 +1,188 @@
+// Package ratelimit enforces the per user quotas of a plan. The daily quotas are kept in Firestore
+// and shared by all function instances, PerMinute is enforced in memory, so it applies per instance.
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	ReasonRateLimit  = "rate_limited"
+	ReasonDailyQuota = "quota_exceeded"
+
+	dayLayout = "2006-01-02"
+	// how long the daily usage read from the store is trusted to reject a user over quota
+	usageCacheTTL = 30 * time.Second
+	// buckets idle longer than a minute are full again, so they can be dropped
+	bucketIdleTTL = time.Minute
+	// how often idle buckets and expired usage are dropped
+	pruneInterval = time.Minute
+	maxBuckets    = 10_000
+	maxUsage      = 10_000
+)
+
+// Decision is the outcome of Limiter.Allow.
+type Decision struct {
+	Allowed    bool
+	Reason     string
+	RetryAfter time.Duration
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type cachedUsage struct {
+	day      string
+	usage    Usage
+	loadedAt time.Time
+}
+
+// Limiter enforces per user Limits. The per minute bucket lives in memory only,
+// daily usage is kept in the Store and cached in memory for usageCacheTTL.
+type Limiter struct {
+	store Store
+	now   func() time.Time
+
+	mu       sync.Mutex
+	buckets  map[string]*bucket
+	usage    map[string]*cachedUsage
+	prunedAt time.Time
+}
+
+func NewLimiter(store Store) *Limiter {
+	return &Limiter{
+		store:   store,
+		now:     time.Now,
+		buckets: make(map[string]*bucket),
+		usage:   make(map[string]*cachedUsage),
+	}
+}
+
+// Allow checks whether the user can send one more message and reserves it in the daily quota,
+// so concurrent requests can't exceed it. Call Record with the tokens when the request is served.
+func (l *Limiter) Allow(ctx context.Context, userID string, limits Limits) (Decision, error) {
+	now := l.now().UTC()
+	day := now.Format(dayLayout)
+	daily := limits.DailyMessages > 0 || limits.DailyTokens > 0
+
+	// users known to be over quota are rejected without a store round trip
+	if daily {
+		if usage, ok := l.cachedUsage(userID, day, now); ok && limits.exceeded(usage) {
+			return Decision{Reason: ReasonDailyQuota, RetryAfter: untilNextDay(now)}, nil
+		}
+	}
+
+	if limits.PerMinute > 0 {
+		if wait := l.take(userID, limits.PerMinute, now); wait > 0 {
+			return Decision{Reason: ReasonRateLimit, RetryAfter: wait}, nil
+		}
+	}
+
+	if daily {
+		usage, reserved, err := l.store.Reserve(ctx, userID, day, limits)
+		if err != nil {
+			return Decision{}, err
+		}
+		l.cacheUsage(userID, day, usage, now)
+		if !reserved {
+			return Decision{Reason: ReasonDailyQuota, RetryAfter: untilNextDay(now)}, nil
+		}
+	}
+	return Decision{Allowed: true}, nil
+}
+
+// Record adds the tokens a served message consumed to the user's daily usage,
+// the message itself was counted by Allow.
+func (l *Limiter) Record(ctx context.Context, userID string, tokens int) error {
+	day := l.now().UTC().Format(dayLayout)
+
+	l.mu.Lock()
+	if cached, ok := l.usage[userID]; ok && cached.day == day {
+		cached.usage.Tokens += tokens
+	}
+	l.mu.Unlock()
+
+	return l.store.Add(ctx, userID, day, Usage{Tokens: tokens})
+}
+
+func (l *Limiter) cachedUsage(userID, day string, now time.Time) (Usage, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	cached, ok := l.usage[userID]
+	if !ok || cached.day != day || now.Sub(cached.loadedAt) >= usageCacheTTL {
+		return Usage{}, false
+	}
+	return cached.usage, true
+}
+
+func (l *Limiter) cacheUsage(userID, day string, usage Usage, now time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.maybePrune(now)
+	if _, ok := l.usage[userID]; !ok && len(l.usage) >= maxUsage {
+		// the cache only saves store round trips, so it is not grown past its bound
+		return
+	}
+	l.usage[userID] = &cachedUsage{day: day, usage: usage, loadedAt: now}
+}
+
+// take removes a token from the user's bucket, refilled at perMinute tokens per minute.
+// It returns how long to wait for the next token when the bucket is empty.
+func (l *Limiter) take(userID string, perMinute int, now time.Time) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	capacity := float64(perMinute)
+	ratePerSecond := capacity / 60
+
+	l.maybePrune(now)
+	b, ok := l.buckets[userID]
+	if !ok {
+		if len(l.buckets) >= maxBuckets {
+			l.prune(now)
+		}
+		b = &bucket{tokens: capacity, last: now}
+		l.buckets[userID] = b
+	}
+
+	b.tokens = min(capacity, b.tokens+now.Sub(b.last).Seconds()*ratePerSecond)
+	b.last = now
+	if b.tokens < 1 {
+		return time.Duration((1 - b.tokens) / ratePerSecond * float64(time.Second))
+	}
+	b.tokens--
+	return 0
+}
+
+// maybePrune prunes once per pruneInterval, must be called with l.mu held
+func (l *Limiter) maybePrune(now time.Time) {
+	if now.Sub(l.prunedAt) >= pruneInterval {
+		l.prune(now)
+	}
+}
+
+// prune drops idle buckets and expired usage, must be called with l.mu held
+func (l *Limiter) prune(now time.Time) {
+	l.prunedAt = now
+	for userID, b := range l.buckets {
+		if now.Sub(b.last) > bucketIdleTTL {
+			delete(l.buckets, userID)
+		}
+	}
+	for userID, cached := range l.usage {
+		if now.Sub(cached.loadedAt) >= usageCacheTTL {
+			delete(l.usage, userID)
+		}
+	}
+}
+
+func untilNextDay(now time.Time) time.Duration {
+	year, month, day := now.Date()
+	return time.Date(year, month, day+1, 0, 0, 0, 0, time.UTC).Sub(now)
+}
@@ -0,0 +1,140 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type memoryStore struct {
+	usage    map[string]Usage
+	reserves int
+}
+
+func (s *memoryStore) Reserve(_ context.Context, userID, day string, limits Limits) (Usage, bool, error) {
+	s.reserves++
+	u := s.usage[userID+"/"+day]
+	if limits.exceeded(u) {
+		return u, false, nil
+	}
+	u.Messages++
+	s.usage[userID+"/"+day] = u
+	return u, true, nil
+}
+
+func (s *memoryStore) Add(_ context.Context, userID, day string, delta Usage) error {
+	u := s.usage[userID+"/"+day]
+	u.Messages += delta.Messages
+	u.Tokens += delta.Tokens
+	s.usage[userID+"/"+day] = u
+	return nil
+}
+
+func newTestLimiter(now *time.Time) (*Limiter, *memoryStore) {
+	store := &memoryStore{usage: map[string]Usage{}}
+	l := NewLimiter(store)
+	l.now = func() time.Time { return *now }
+	return l, store
+}
+
+func TestLimiterPerMinute(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	l, _ := newTestLimiter(&now)
+	limits := Limits{PerMinute: 2}
+
+	for range 2 {
+		d, err := l.Allow(ctx, "user", limits)
+		require.NoError(t, err)
+		assert.True(t, d.Allowed)
+	}
+
+	d, err := l.Allow(ctx, "user", limits)
+	require.NoError(t, err)
+	assert.False(t, d.Allowed)
+	assert.Equal(t, ReasonRateLimit, d.Reason)
+	assert.Equal(t, 30*time.Second, d.RetryAfter)
+
+	// other users have their own bucket
+	d, err = l.Allow(ctx, "other", limits)
+	require.NoError(t, err)
+	assert.True(t, d.Allowed)
+
+	now = now.Add(30 * time.Second)
+	d, err = l.Allow(ctx, "user", limits)
+	require.NoError(t, err)
+	assert.True(t, d.Allowed)
+}
+
+func TestLimiterDailyQuota(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 23, 0, 0, 0, time.UTC)
+	l, store := newTestLimiter(&now)
+
+	tests := []struct {
+		name    string
+		limits  Limits
+		tokens  int
+		allowed bool
+	}{
+		{name: "under quota", limits: Limits{DailyMessages: 2, DailyTokens: 1000}, tokens: 100, allowed: true},
+		{name: "messages exceeded", limits: Limits{DailyMessages: 1}, tokens: 0, allowed: false},
+		{name: "tokens exceeded", limits: Limits{DailyTokens: 100}, tokens: 100, allowed: false},
+		{name: "no limits", limits: Limits{}, tokens: 100, allowed: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			userID := tt.name
+			d, err := l.Allow(ctx, userID, tt.limits)
+			require.NoError(t, err)
+			require.True(t, d.Allowed)
+			require.NoError(t, l.Record(ctx, userID, tt.tokens))
+
+			d, err = l.Allow(ctx, userID, tt.limits)
+			require.NoError(t, err)
+			assert.Equal(t, tt.allowed, d.Allowed)
+			if !tt.allowed {
+				assert.Equal(t, ReasonDailyQuota, d.Reason)
+				assert.Equal(t, time.Hour, d.RetryAfter)
+			}
+		})
+	}
+
+	// the message is counted when it is allowed, not when it is recorded
+	assert.Equal(t, Usage{Messages: 2, Tokens: 100}, store.usage["under quota/2025-05-01"])
+
+	// users over quota are rejected from the cache until it expires
+	reserves := store.reserves
+	_, err := l.Allow(ctx, "messages exceeded", Limits{DailyMessages: 1})
+	require.NoError(t, err)
+	assert.Equal(t, reserves, store.reserves)
+
+	now = now.Add(usageCacheTTL)
+	_, err = l.Allow(ctx, "messages exceeded", Limits{DailyMessages: 1})
+	require.NoError(t, err)
+	assert.Equal(t, reserves+1, store.reserves)
+}
+
+func TestLimiterPrune(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	l, _ := newTestLimiter(&now)
+	limits := Limits{PerMinute: 5, DailyMessages: 10}
+
+	for _, userID := range []string{"a", "b"} {
+		_, err := l.Allow(ctx, userID, limits)
+		require.NoError(t, err)
+	}
+	assert.Len(t, l.buckets, 2)
+	assert.Len(t, l.usage, 2)
+
+	// idle users are dropped by the next request after the prune interval
+	now = now.Add(pruneInterval + time.Second)
+	_, err := l.Allow(ctx, "c", limits)
+	require.NoError(t, err)
+	assert.Len(t, l.buckets, 1)
+	assert.Len(t, l.usage, 1)
+}
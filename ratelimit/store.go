@@ -0,0 +1,78 @@
+package ratelimit
+
+import (
+	"context"
+
+	"cloud.google.com/go/firestore"
+	"github.com/klipach/matchguru/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	firestoreUserCollection  = "users"
+	firestoreQuotaCollection = "quotas"
+)
+
+// Usage is what a user consumed during a day.
+type Usage struct {
+	Messages int `firestore:"messages"`
+	Tokens   int `firestore:"tokens"`
+}
+
+// Store persists daily usage, so quotas are shared by all function instances.
+type Store interface {
+	// Reserve counts one more message unless the usage already exceeds the limits,
+	// it returns the usage including the reserved message and whether it was reserved.
+	Reserve(ctx context.Context, userID, day string, limits Limits) (Usage, bool, error)
+	Add(ctx context.Context, userID, day string, delta Usage) error
+}
+
+// FirestoreStore keeps daily usage in users/{userID}/quotas/{day} documents.
+type FirestoreStore struct{}
+
+func (FirestoreStore) Reserve(ctx context.Context, userID, day string, limits Limits) (Usage, bool, error) {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return Usage{}, false, err
+	}
+	ref := quotaDoc(client, userID, day)
+	var usage Usage
+	var reserved bool
+	err = client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		usage, reserved = Usage{}, false
+		doc, err := tx.Get(ref)
+		switch {
+		case status.Code(err) == codes.NotFound:
+		case err != nil:
+			return err
+		default:
+			if err := doc.DataTo(&usage); err != nil {
+				return err
+			}
+		}
+		if limits.exceeded(usage) {
+			return nil
+		}
+		usage.Messages++
+		reserved = true
+		return tx.Set(ref, map[string]any{"messages": firestore.Increment(1)}, firestore.MergeAll)
+	})
+	return usage, reserved, err
+}
+
+func (FirestoreStore) Add(ctx context.Context, userID, day string, delta Usage) error {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return err
+	}
+	_, err = quotaDoc(client, userID, day).Set(ctx, map[string]any{
+		"messages": firestore.Increment(delta.Messages),
+		"tokens":   firestore.Increment(delta.Tokens),
+	}, firestore.MergeAll)
+	return err
+}
+
+func quotaDoc(client *firestore.Client, userID, day string) *firestore.DocumentRef {
+	return client.Collection(firestoreUserCollection).Doc(userID).Collection(firestoreQuotaCollection).Doc(day)
+}
@@ -0,0 +1,97 @@
+package matchguru
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+
+	fbauth "firebase.google.com/go/v4/auth"
+	"github.com/klipach/matchguru/auth"
+	"github.com/klipach/matchguru/config"
+	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/store"
+	"github.com/klipach/matchguru/telemetry"
+	"github.com/klipach/matchguru/usage"
+)
+
+// NewHandler routes the requests served by the function, everything not matched by
+// a more specific pattern is a chat message answered by the bot.
+// It is used both by the Cloud Functions entry point and by cmd/server.
+func NewHandler(cfg *config.Config) (http.Handler, error) {
+	log.SetLevel(cfg.LogLevel)
+	log.SetPolicy(cfg.Log)
+	usage.SetPrices(cfg.OpenAIPrices)
+	store.SetProjectID(cfg.ProjectID)
+
+	b, err := newBot(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /usage", authenticated(getUsage))
+	mux.HandleFunc("GET /chats", authenticated(listChats))
+	mux.HandleFunc("POST /chats", authenticated(createChat))
+	mux.HandleFunc("PATCH /chats/{chatID}", authenticated(renameChat))
+	mux.HandleFunc("DELETE /chats/{chatID}", authenticated(deleteChat))
+	mux.HandleFunc("GET /chats/{chatID}/messages", authenticated(listMessages))
+	mux.HandleFunc("POST /chats/{chatID}/regenerate", b.regenerate)
+	mux.HandleFunc("POST /chats/{chatID}/edit", b.edit)
+	mux.HandleFunc("POST /feedback", authenticated(b.submitFeedback))
+	mux.HandleFunc("GET /preferences", authenticated(getPreferences))
+	mux.HandleFunc("PUT /preferences", authenticated(putPreferences))
+	mux.HandleFunc("GET /follows", authenticated(listFollows))
+	mux.HandleFunc("PUT /follows/{type}/{id}", authenticated(addFollow))
+	mux.HandleFunc("DELETE /follows/{type}/{id}", authenticated(removeFollow))
+	mux.HandleFunc("POST /devices", authenticated(registerDevice))
+	mux.HandleFunc("DELETE /devices/{token}", authenticated(unregisterDevice))
+	mux.HandleFunc("GET /account/export", authenticated(exportAccount))
+	mux.HandleFunc("DELETE /account", authenticated(deleteAccount))
+	mux.Handle("/", b)
+	return telemetry.Middleware(cfg.ProjectID, recoverPanics(mux)), nil
+}
+
+// recoverPanics turns a panic in a handler into a logged 500 instead of a crashed instance.
+func recoverPanics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() {
+			if rec := recover(); rec != nil {
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				log.LoggerFromContext(r.Context()).Error("panic while serving request",
+					slog.Any(ErrorMsgLogField, rec),
+					slog.String("stack", string(debug.Stack())),
+				)
+				// if the stream is already started, the status can't be changed anymore
+				http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+			}
+		}()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticated verifies the Firebase ID token before calling h,
+// the logger in the request context gets the user ID.
+func authenticated(h func(http.ResponseWriter, *http.Request, *fbauth.Token)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		logger := log.LoggerFromContext(r.Context())
+		token, err := auth.Authenticate(r)
+		if err != nil {
+			logger.Error("error while authenticating", slog.String(ErrorMsgLogField, err.Error()))
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		logger = logger.With(slog.String(userIDLogField, log.UserID(token.UID)))
+		h(w, r.WithContext(log.WithLogger(r.Context(), logger)), token)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, r *http.Request, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.LoggerFromContext(r.Context()).Error("error while encoding response", slog.String(ErrorMsgLogField, err.Error()))
+	}
+}
@@ -0,0 +1,30 @@
+package sport
+
+import "fmt"
+
+// Sport selects the fixture API, the entity dictionaries and the prompt a chat is answered with.
+type Sport string
+
+const (
+	Football Sport = "football"
+	Cricket  Sport = "cricket"
+
+	// Default is the sport of requests without one, the bot was football only at first
+	Default = Football
+)
+
+// All lists the supported sports.
+var All = []Sport{Football, Cricket}
+
+// Parse returns the sport named s, an empty name is the default sport.
+func Parse(s string) (Sport, error) {
+	if s == "" {
+		return Default, nil
+	}
+	for _, sp := range All {
+		if string(sp) == s {
+			return sp, nil
+		}
+	}
+	return "", fmt.Errorf("unknown sport %q", s)
+}
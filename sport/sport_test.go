@@ -0,0 +1,34 @@
+package sport
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name     string
+		input    string
+		expected Sport
+		err      bool
+	}{
+		{name: "empty is football", input: "", expected: Football},
+		{name: "football", input: "football", expected: Football},
+		{name: "cricket", input: "cricket", expected: Cricket},
+		{name: "basketball has no fixture API yet", input: "basketball", err: true},
+		{name: "soccer is not a sport name", input: "soccer", err: true},
+		{name: "case sensitive", input: "Cricket", err: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.input)
+			if tt.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.expected, s)
+		})
+	}
+}
@@ -0,0 +1,84 @@
+package sse
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+var ErrStreamingUnsupported = errors.New("streaming unsupported")
+
+// Writer writes Server-Sent Events to an http.ResponseWriter, flushing after every event.
+type Writer struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	started bool
+}
+
+func NewWriter(w http.ResponseWriter) (*Writer, error) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, ErrStreamingUnsupported
+	}
+	return &Writer{w: w, flusher: flusher}, nil
+}
+
+// Start sends the SSE headers to the client, after that the response status can't be changed.
+func (s *Writer) Start() {
+	s.StartWithStatus(http.StatusOK)
+}
+
+// StartWithStatus is like Start, but responds with the given status code,
+// it lets errors such as 429 carry a structured event in the body.
+func (s *Writer) StartWithStatus(statusCode int) {
+	if s.started {
+		return
+	}
+	s.started = true
+	s.w.Header().Set("Content-Type", "text/event-stream")
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.Header().Set("Connection", "keep-alive")
+	s.w.WriteHeader(statusCode)
+	s.flusher.Flush()
+}
+
+// Started reports whether the SSE headers were already sent.
+func (s *Writer) Started() bool {
+	return s.started
+}
+
+// Data sends an unnamed event, which clients receive as a default "message" event.
+func (s *Writer) Data(v any) error {
+	return s.Event("", v)
+}
+
+// Event sends v encoded as JSON in a named event.
+func (s *Writer) Event(name string, v any) error {
+	s.Start()
+	jsonData, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var sseData string
+	if name != "" {
+		sseData = fmt.Sprintf("event: %s\ndata: %s\n\n", name, jsonData)
+	} else {
+		sseData = fmt.Sprintf("data: %s\n\n", jsonData)
+	}
+	if _, err := s.w.Write([]byte(sseData)); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
+
+// Heartbeat sends a comment, which clients ignore, so proxies don't close a stream idle between events.
+func (s *Writer) Heartbeat() error {
+	s.Start()
+	if _, err := s.w.Write([]byte(": heartbeat\n\n")); err != nil {
+		return err
+	}
+	s.flusher.Flush()
+	return nil
+}
@@ -0,0 +1,63 @@
+package sse
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	tests := []struct {
+		name     string
+		write    func(sw *Writer) error
+		expected string
+	}{
+		{
+			name: "data event",
+			write: func(sw *Writer) error {
+				return sw.Data(map[string]string{"response": "hi"})
+			},
+			expected: "data: {\"response\":\"hi\"}\n\n",
+		},
+		{
+			name: "named event",
+			write: func(sw *Writer) error {
+				return sw.Event("thinking", map[string]string{"status": "thinking"})
+			},
+			expected: "event: thinking\ndata: {\"status\":\"thinking\"}\n\n",
+		},
+		{
+			name: "heartbeat",
+			write: func(sw *Writer) error {
+				return sw.Heartbeat()
+			},
+			expected: ": heartbeat\n\n",
+		},
+		{
+			name: "start only",
+			write: func(sw *Writer) error {
+				sw.Start()
+				return nil
+			},
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			sw, err := NewWriter(rec)
+			require.NoError(t, err)
+
+			require.NoError(t, tt.write(sw))
+			assert.True(t, sw.Started())
+			assert.Equal(t, http.StatusOK, rec.Code)
+			assert.Equal(t, "text/event-stream", rec.Header().Get("Content-Type"))
+			assert.True(t, rec.Flushed)
+			assert.Equal(t, tt.expected, rec.Body.String())
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package store
+
+import (
+	"context"
+	"sync"
+
+	"cloud.google.com/go/compute/metadata"
+	"cloud.google.com/go/firestore"
+)
+
+var (
+	clientMu  sync.Mutex
+	client    *firestore.Client
+	projectID string
+)
+
+// SetProjectID sets the project of the Firestore database, it must be called before the first Client call.
+// When not set, the project is resolved from the metadata server.
+func SetProjectID(id string) {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+	projectID = id
+}
+
+// Client returns a Firestore client shared by all requests served by this instance.
+// The client is created on first use; failed attempts are not cached, so a transient
+// error during a cold start does not poison the instance.
+func Client(ctx context.Context) (*firestore.Client, error) {
+	clientMu.Lock()
+	defer clientMu.Unlock()
+
+	if client != nil {
+		return client, nil
+	}
+
+	if projectID == "" {
+		id, err := metadata.ProjectIDWithContext(ctx)
+		if err != nil {
+			return nil, err
+		}
+		projectID = id
+	}
+
+	// the client outlives the request, so it must not be bound to the request context
+	c, err := firestore.NewClient(context.WithoutCancel(ctx), projectID)
+	if err != nil {
+		return nil, err
+	}
+	client = c
+	return client, nil
+}
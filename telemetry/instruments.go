@@ -0,0 +1,104 @@
+package telemetry
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/metric"
+)
+
+// Request statuses, besides the rate limit reasons.
+const (
+	StatusOK           = "ok"
+	StatusError        = "error"
+	StatusBadRequest   = "bad_request"
+	StatusUnauthorized = "unauthorized"
+	StatusConflict     = "conflict"
+)
+
+// the global meter delegates to the provider installed by SetupMetrics, even when set later
+var meter = otel.Meter("github.com/klipach/matchguru")
+
+// instruments are created once, errors only happen with invalid names and leave a no-op instrument
+var (
+	requests, _ = meter.Int64Counter("matchguru.requests",
+		metric.WithDescription("Bot requests by answer mode and status."),
+		metric.WithUnit("{request}"))
+	timeToFirstToken, _ = meter.Float64Histogram("matchguru.time_to_first_token",
+		metric.WithDescription("Time from the OpenAI request to the first streamed chunk."),
+		metric.WithUnit("s"))
+	streamDuration, _ = meter.Float64Histogram("matchguru.stream.duration",
+		metric.WithDescription("Time from the OpenAI request to the end of the stream."),
+		metric.WithUnit("s"))
+	tokens, _ = meter.Int64Histogram("matchguru.response.tokens",
+		metric.WithDescription("Tokens used per OpenAI response."),
+		metric.WithUnit("{token}"))
+	internalLinks, _ = meter.Int64Counter("matchguru.internal_links",
+		metric.WithDescription("Internal links in answers by entity type, resolved or not."),
+		metric.WithUnit("{link}"))
+	externalLinksStripped, _ = meter.Int64Counter("matchguru.external_links.stripped",
+		metric.WithDescription("External links removed from answers."),
+		metric.WithUnit("{link}"))
+	fixtureErrors, _ = meter.Int64Counter("matchguru.fixture.errors",
+		metric.WithDescription("Failed SportMonks fixture fetches."),
+		metric.WithUnit("{error}"))
+	refusals, _ = meter.Int64Counter("matchguru.refusals",
+		metric.WithDescription("Answers refusing an off-topic question."),
+		metric.WithUnit("{answer}"))
+)
+
+// RecordRequest counts a bot request once its outcome is known.
+func RecordRequest(ctx context.Context, mode, status string) {
+	requests.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("mode", mode),
+		attribute.String("status", status),
+	))
+}
+
+// RecordGeneration records the time to first token, when a chunk was streamed, and the total stream duration.
+func RecordGeneration(ctx context.Context, model string, ttft, total time.Duration) {
+	attrs := metric.WithAttributes(attribute.String("model", model))
+	if ttft > 0 {
+		timeToFirstToken.Record(ctx, ttft.Seconds(), attrs)
+	}
+	streamDuration.Record(ctx, total.Seconds(), attrs)
+}
+
+// RecordTokens records the prompt and completion tokens of a response.
+func RecordTokens(ctx context.Context, model string, promptTokens, completionTokens int) {
+	tokens.Record(ctx, int64(promptTokens), metric.WithAttributes(
+		attribute.String("model", model),
+		attribute.String("type", "prompt"),
+	))
+	tokens.Record(ctx, int64(completionTokens), metric.WithAttributes(
+		attribute.String("model", model),
+		attribute.String("type", "completion"),
+	))
+}
+
+// RecordInternalLink counts an internal link, resolved tells a link from a "link mapping not found" miss.
+func RecordInternalLink(ctx context.Context, entityType string, resolved bool) {
+	internalLinks.Add(ctx, 1, metric.WithAttributes(
+		attribute.String("entity_type", entityType),
+		attribute.Bool("resolved", resolved),
+	))
+}
+
+// RecordExternalLinksStripped counts the external links removed from an answer.
+func RecordExternalLinksStripped(ctx context.Context, n int) {
+	if n > 0 {
+		externalLinksStripped.Add(ctx, int64(n))
+	}
+}
+
+// RecordFixtureError counts a failed fixture fetch.
+func RecordFixtureError(ctx context.Context) {
+	fixtureErrors.Add(ctx, 1)
+}
+
+// RecordRefusal counts an answer refusing an off-topic question.
+func RecordRefusal(ctx context.Context, model string) {
+	refusals.Add(ctx, 1, metric.WithAttributes(attribute.String("model", model)))
+}
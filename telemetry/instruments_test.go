@@ -0,0 +1,48 @@
+package telemetry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+	"go.opentelemetry.io/otel/sdk/metric/metricdata"
+)
+
+func TestInstruments(t *testing.T) {
+	reader := sdkmetric.NewManualReader()
+	otel.SetMeterProvider(sdkmetric.NewMeterProvider(sdkmetric.WithReader(reader)))
+
+	ctx := context.Background()
+	RecordRequest(ctx, "new", StatusOK)
+	RecordRequest(ctx, "new", StatusOK)
+	RecordGeneration(ctx, "model", 0, time.Second)
+	RecordInternalLink(ctx, "team", true)
+	RecordInternalLink(ctx, "unknown", false)
+	RecordExternalLinksStripped(ctx, 0)
+
+	var rm metricdata.ResourceMetrics
+	require.NoError(t, reader.Collect(ctx, &rm))
+	require.Len(t, rm.ScopeMetrics, 1)
+	metrics := map[string]metricdata.Aggregation{}
+	for _, m := range rm.ScopeMetrics[0].Metrics {
+		metrics[m.Name] = m.Data
+	}
+
+	requests := metrics["matchguru.requests"].(metricdata.Sum[int64])
+	require.Len(t, requests.DataPoints, 1)
+	assert.Equal(t, int64(2), requests.DataPoints[0].Value)
+	status, _ := requests.DataPoints[0].Attributes.Value(attribute.Key("status"))
+	assert.Equal(t, StatusOK, status.AsString())
+
+	// no chunk was streamed, so there is no time to first token
+	assert.NotContains(t, metrics, "matchguru.time_to_first_token")
+	assert.Contains(t, metrics, "matchguru.stream.duration")
+
+	assert.Len(t, metrics["matchguru.internal_links"].(metricdata.Sum[int64]).DataPoints, 2)
+	assert.NotContains(t, metrics, "matchguru.external_links.stripped")
+}
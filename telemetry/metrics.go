@@ -0,0 +1,66 @@
+package telemetry
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+
+	mexporter "github.com/GoogleCloudPlatform/opentelemetry-operations-go/exporter/metric"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/prometheus"
+	sdkmetric "go.opentelemetry.io/otel/sdk/metric"
+)
+
+const (
+	// ExporterCloudMonitoring sends metrics to Cloud Monitoring.
+	ExporterCloudMonitoring = "cloudmonitoring"
+	// ExporterPrometheus serves metrics for scraping, only cmd/server exposes the endpoint.
+	ExporterPrometheus = "prometheus"
+
+	metricsExportInterval = time.Minute
+)
+
+// Metrics configures where metrics are exported.
+type Metrics struct {
+	Exporter string `json:"exporter"`
+}
+
+// SetupMetrics installs the global meter provider. The returned handler serves
+// the metrics for scraping, it is nil unless the exporter is Prometheus.
+// The returned function flushes the metrics not exported yet, it is called on shutdown.
+func SetupMetrics(ctx context.Context, projectID string, cfg Metrics) (func(context.Context) error, http.Handler, error) {
+	var reader sdkmetric.Reader
+	var handler http.Handler
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil, nil
+	case ExporterCloudMonitoring:
+		exporter, err := mexporter.New(mexporter.WithProjectID(projectID))
+		if err != nil {
+			return nil, nil, fmt.Errorf("creating Cloud Monitoring exporter: %w", err)
+		}
+		reader = sdkmetric.NewPeriodicReader(exporter, sdkmetric.WithInterval(metricsExportInterval))
+	case ExporterPrometheus:
+		exporter, err := prometheus.New()
+		if err != nil {
+			return nil, nil, err
+		}
+		reader = exporter
+		handler = promhttp.Handler()
+	default:
+		return nil, nil, fmt.Errorf("unknown metrics exporter %q", cfg.Exporter)
+	}
+
+	res, err := newResource(projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	provider := sdkmetric.NewMeterProvider(
+		sdkmetric.WithReader(reader),
+		sdkmetric.WithResource(res),
+	)
+	otel.SetMeterProvider(provider)
+	return provider.Shutdown, handler, nil
+}
@@ -0,0 +1,246 @@
+package telemetry
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/klipach/matchguru/log"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"golang.org/x/oauth2/google"
+)
+
+const (
+	serviceName = "matchguru"
+
+	// ExporterNone only propagates the incoming trace, spans are not recorded.
+	ExporterNone = "none"
+	// ExporterCloudTrace sends spans to Cloud Trace through its OTLP endpoint.
+	ExporterCloudTrace = "cloudtrace"
+	// ExporterOTLP sends spans to the collector set by the standard OTEL_EXPORTER_OTLP_* env variables.
+	ExporterOTLP = "otlp"
+
+	cloudTraceEndpoint = "https://telemetry.googleapis.com/v1/traces"
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+	cloudTraceHeader   = "X-Cloud-Trace-Context"
+	traceparentHeader  = "traceparent"
+
+	urlFullAttribute = "url.full"
+	redacted         = "REDACTED"
+)
+
+// sensitiveQueryParams carry credentials, such as the API key of the SportMonks cricket API
+var sensitiveQueryParams = []string{"api_token"}
+
+// Tracing configures where spans are exported.
+type Tracing struct {
+	Exporter string `json:"exporter"`
+	// SampleRatio is the share of new traces recorded, requests with a sampled parent are always recorded
+	SampleRatio float64 `json:"sample_ratio"`
+}
+
+// Setup installs the global tracer provider and the W3C trace context propagator.
+// The returned function flushes the spans still buffered, it is called on shutdown.
+func Setup(ctx context.Context, projectID string, cfg Tracing) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	var exporter sdktrace.SpanExporter
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterCloudTrace:
+		client, err := google.DefaultClient(ctx, cloudPlatformScope)
+		if err != nil {
+			return nil, fmt.Errorf("creating Cloud Trace client: %w", err)
+		}
+		exporter, err = otlptracehttp.New(ctx,
+			otlptracehttp.WithEndpointURL(cloudTraceEndpoint),
+			otlptracehttp.WithHTTPClient(client),
+		)
+		if err != nil {
+			return nil, err
+		}
+	case ExporterOTLP:
+		var err error
+		exporter, err = otlptracehttp.New(ctx)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+
+	res, err := newResource(projectID)
+	if err != nil {
+		return nil, err
+	}
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return provider.Shutdown, nil
+}
+
+// instanceID tells the function instances apart, Cloud Monitoring rejects cumulative points
+// of instances writing the same time series
+var instanceID = uuid.NewString()
+
+func newResource(projectID string) (*resource.Resource, error) {
+	res, err := resource.New(context.Background(),
+		resource.WithHost(),
+		resource.WithAttributes(
+			attribute.String("service.name", serviceName),
+			attribute.String("service.instance.id", instanceID),
+			// Cloud Trace stores the spans in the project of this attribute
+			attribute.String("gcp.project_id", projectID),
+		),
+	)
+	if err != nil {
+		return nil, err
+	}
+	return resource.Merge(resource.Default(), res)
+}
+
+// Middleware starts the server span of each request and correlates the request logs with it.
+// Requests coming through Google load balancers only carry X-Cloud-Trace-Context,
+// it is used as the parent when there is no traceparent header.
+// The span is named after the route pattern matched by the ServeMux of next, not the path,
+// which carries IDs and device tokens.
+func Middleware(projectID string, next http.Handler) http.Handler {
+	withLogTrace := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if sc := trace.SpanContextFromContext(r.Context()); sc.IsValid() {
+			r = r.WithContext(log.WithTrace(r.Context(), log.Trace{
+				ProjectID: projectID,
+				TraceID:   sc.TraceID().String(),
+				SpanID:    sc.SpanID().String(),
+				Sampled:   sc.IsSampled(),
+			}))
+		}
+		next.ServeHTTP(w, r)
+		// the ServeMux sets the pattern on the request once routed
+		if r.Pattern != "" {
+			trace.SpanFromContext(r.Context()).SetName(spanName(r))
+		}
+	})
+	instrumented := otelhttp.NewHandler(withLogTrace, serviceName,
+		otelhttp.WithSpanNameFormatter(func(_ string, r *http.Request) string {
+			return spanName(r)
+		}),
+	)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(traceparentHeader) == "" && r.Header.Get(cloudTraceHeader) != "" {
+			if t, ok := log.ParseTrace(r.Header); ok {
+				// TRACE_ID and TRACE_ID;o=1 have no span, a parent span ID is made up to keep the trace
+				if t.SpanID == "" {
+					t.SpanID = newSpanID()
+				}
+				r.Header.Set(traceparentHeader, traceparent(t))
+			}
+		}
+		instrumented.ServeHTTP(w, r)
+	})
+}
+
+// spanName is the route pattern such as "DELETE /devices/{token}", the method until the request is routed.
+func spanName(r *http.Request) string {
+	switch {
+	case r.Pattern == "":
+		return r.Method
+	case strings.Contains(r.Pattern, " "):
+		return r.Pattern
+	default:
+		// patterns without method, such as the "/" catch-all
+		return r.Method + " " + r.Pattern
+	}
+}
+
+func newSpanID() string {
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// Transport instruments outgoing requests with client spans and propagates the trace.
+// Credentials in the query are redacted from the URL recorded on the span.
+func Transport(rt http.RoundTripper) http.RoundTripper {
+	return otelhttp.NewTransport(redactingTransport{rt: rt})
+}
+
+// redactingTransport runs inside the otelhttp transport, after the client span recorded the full URL,
+// and overwrites it with the redacted one.
+type redactingTransport struct {
+	rt http.RoundTripper
+}
+
+func (t redactingTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if hasSensitiveQuery(r.URL) {
+		trace.SpanFromContext(r.Context()).SetAttributes(attribute.String(urlFullAttribute, RedactURL(r.URL.String())))
+	}
+	return t.rt.RoundTrip(r)
+}
+
+func hasSensitiveQuery(u *url.URL) bool {
+	if u.RawQuery == "" {
+		return false
+	}
+	query := u.Query()
+	for _, param := range sensitiveQueryParams {
+		if query.Has(param) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactURL replaces the values of the query parameters carrying credentials,
+// a URL that can't be parsed loses its whole query.
+func RedactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		before, _, _ := strings.Cut(raw, "?")
+		return before
+	}
+	if !hasSensitiveQuery(u) {
+		return raw
+	}
+	query := u.Query()
+	for _, param := range sensitiveQueryParams {
+		if query.Has(param) {
+			query.Set(param, redacted)
+		}
+	}
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// RedactError redacts the URL of a request error, such errors are logged.
+func RedactError(err error) error {
+	var urlErr *url.Error
+	if !errors.As(err, &urlErr) {
+		return err
+	}
+	return &url.Error{Op: urlErr.Op, URL: RedactURL(urlErr.URL), Err: urlErr.Err}
+}
+
+func traceparent(t log.Trace) string {
+	flags := "00"
+	if t.Sampled {
+		flags = "01"
+	}
+	return "00-" + t.TraceID + "-" + t.SpanID + "-" + flags
+}
@@ -0,0 +1,163 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/klipach/matchguru/log"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestMiddleware(t *testing.T) {
+	_, err := Setup(context.Background(), "project", Tracing{Exporter: ExporterNone})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name     string
+		headers  map[string]string
+		expected log.Trace
+		ok       bool
+	}{
+		{
+			name:     "traceparent",
+			headers:  map[string]string{"traceparent": "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"},
+			expected: log.Trace{ProjectID: "project", TraceID: "4bf92f3577b34da6a3ce929d0e0e4736", SpanID: "00f067aa0ba902b7", Sampled: true},
+			ok:       true,
+		},
+		{
+			name:     "cloud trace context",
+			headers:  map[string]string{"X-Cloud-Trace-Context": "105445aa7843bc8bf206b12000100000/1;o=0"},
+			expected: log.Trace{ProjectID: "project", TraceID: "105445aa7843bc8bf206b12000100000", SpanID: "0000000000000001"},
+			ok:       true,
+		},
+		{
+			// the span ID is generated
+			name:     "cloud trace context without span",
+			headers:  map[string]string{"X-Cloud-Trace-Context": "105445aa7843bc8bf206b12000100000;o=1"},
+			expected: log.Trace{ProjectID: "project", TraceID: "105445aa7843bc8bf206b12000100000", Sampled: true},
+			ok:       true,
+		},
+		{name: "no trace"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var trace log.Trace
+			var ok bool
+			handler := Middleware("project", http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				trace, ok = log.TraceFromContext(r.Context())
+			}))
+
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), req)
+			assert.Equal(t, tt.ok, ok)
+			if tt.ok && tt.expected.SpanID == "" {
+				assert.Len(t, trace.SpanID, 16)
+				trace.SpanID = ""
+			}
+			assert.Equal(t, tt.expected, trace)
+		})
+	}
+}
+
+func TestMiddlewareSpanName(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("DELETE /devices/{token}", func(http.ResponseWriter, *http.Request) {})
+	mux.HandleFunc("/", func(http.ResponseWriter, *http.Request) {})
+	handler := Middleware("project", mux)
+
+	tests := []struct {
+		method   string
+		path     string
+		expected string
+	}{
+		{http.MethodDelete, "/devices/fcm-token", "DELETE /devices/{token}"},
+		{http.MethodPost, "/", "POST /"},
+		{http.MethodPost, "/bot", "POST /"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expected, func(t *testing.T) {
+			handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(tt.method, tt.path, nil))
+			spans := recorder.Ended()
+			require.NotEmpty(t, spans)
+			assert.Equal(t, tt.expected, spans[len(spans)-1].Name())
+		})
+	}
+}
+
+func TestSetupUnknownExporter(t *testing.T) {
+	_, err := Setup(context.Background(), "project", Tracing{Exporter: "jaeger"})
+	assert.Error(t, err)
+}
+
+func TestRedactURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		url      string
+		expected string
+	}{
+		{"api token", "https://cricket.sportmonks.com/api/v2.0/fixtures/42?api_token=secret&include=venue", "https://cricket.sportmonks.com/api/v2.0/fixtures/42?api_token=REDACTED&include=venue"},
+		{"no credentials", "https://api.sportmonks.com/v3/football/fixtures/1?include=league", "https://api.sportmonks.com/v3/football/fixtures/1?include=league"},
+		{"no query", "https://api.sportmonks.com/v3/football/fixtures/1", "https://api.sportmonks.com/v3/football/fixtures/1"},
+		{"invalid", "http://[::1%zz/x?api_token=secret", "http://[::1%zz/x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, RedactURL(tt.url))
+		})
+	}
+}
+
+func TestTransportRedactsSpanURL(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer server.Close()
+
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+	ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+	defer parent.End()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/fixtures/42?api_token=secret", http.NoBody)
+	require.NoError(t, err)
+	resp, err := (&http.Client{Transport: Transport(http.DefaultTransport)}).Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+
+	spans := recorder.Ended()
+	require.Len(t, spans, 1)
+	var full string
+	for _, attr := range spans[0].Attributes() {
+		if attr.Key == urlFullAttribute {
+			full = attr.Value.AsString()
+		}
+	}
+	assert.Equal(t, server.URL+"/fixtures/42?api_token=REDACTED", full)
+}
+
+func TestNewResource(t *testing.T) {
+	res, err := newResource("project")
+	require.NoError(t, err)
+
+	attrs := map[attribute.Key]string{}
+	for _, kv := range res.Attributes() {
+		attrs[kv.Key] = kv.Value.Emit()
+	}
+	assert.Equal(t, serviceName, attrs["service.name"])
+	assert.Equal(t, instanceID, attrs["service.instance.id"])
+	assert.Equal(t, "project", attrs["gcp.project_id"])
+	assert.NotEmpty(t, attrs["host.name"])
+}
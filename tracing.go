@@ -0,0 +1,60 @@
+package matchguru
+
+import (
+	"context"
+	"time"
+
+	"github.com/klipach/matchguru/telemetry"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("github.com/klipach/matchguru")
+
+// endSpan ends the span of a phase, marking it failed when err is set.
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// generation splits the OpenAI generation in the wait for the first token and the streaming of the rest,
+// as spans and as the time to first token and stream duration metrics.
+type generation struct {
+	ctx       context.Context
+	model     string
+	start     time.Time
+	ttft      time.Duration
+	generate  trace.Span
+	firstSpan trace.Span
+	streaming trace.Span
+}
+
+func startGeneration(ctx context.Context, model string) (context.Context, *generation) {
+	ctx, generate := tracer.Start(ctx, "bot.generate")
+	_, firstSpan := tracer.Start(ctx, "bot.time_to_first_token")
+	return ctx, &generation{ctx: ctx, model: model, start: time.Now(), generate: generate, firstSpan: firstSpan}
+}
+
+// chunk is called for every streamed chunk, the first one ends the time to first token.
+func (g *generation) chunk() {
+	if g.streaming != nil {
+		return
+	}
+	g.ttft = time.Since(g.start)
+	g.firstSpan.End()
+	_, g.streaming = tracer.Start(g.ctx, "bot.streaming")
+}
+
+func (g *generation) end(err error) {
+	if g.streaming == nil {
+		endSpan(g.firstSpan, err)
+	} else {
+		endSpan(g.streaming, err)
+	}
+	endSpan(g.generate, err)
+	telemetry.RecordGeneration(g.ctx, g.model, g.ttft, time.Since(g.start))
+}
@@ -0,0 +1,60 @@
+package matchguru
+
+import (
+	"log/slog"
+	"net/http"
+	"time"
+
+	fbauth "firebase.google.com/go/v4/auth"
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/log"
+	"github.com/klipach/matchguru/usage"
+)
+
+const (
+	usageDateLayout    = "2006-01-02"
+	defaultUsagePeriod = 30 * 24 * time.Hour
+)
+
+// getUsage returns the user's token usage and cost between the from and to dates (inclusive),
+// by default for the last 30 days.
+func getUsage(w http.ResponseWriter, r *http.Request, token *fbauth.Token) {
+	ctx := r.Context()
+	logger := log.LoggerFromContext(ctx)
+
+	to := time.Now().UTC().Truncate(24 * time.Hour)
+	from := to.Add(-defaultUsagePeriod)
+	var err error
+	if v := r.URL.Query().Get("to"); v != "" {
+		if to, err = time.Parse(usageDateLayout, v); err != nil {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+	if v := r.URL.Query().Get("from"); v != "" {
+		if from, err = time.Parse(usageDateLayout, v); err != nil {
+			http.Error(w, "Bad Request", http.StatusBadRequest)
+			return
+		}
+	}
+	if from.After(to) {
+		http.Error(w, "Bad Request", http.StatusBadRequest)
+		return
+	}
+
+	entries, err := usage.List(ctx, token.UID, from, to.Add(24*time.Hour))
+	if err != nil {
+		logger.Error("error while loading usage", slog.String(ErrorMsgLogField, err.Error()))
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+
+	total, chats, days := usage.Summarize(entries)
+	writeJSON(w, r, http.StatusOK, contract.UsageResponse{
+		From:  from.Format(usageDateLayout),
+		To:    to.Format(usageDateLayout),
+		Total: total,
+		Chats: chats,
+		Days:  days,
+	})
+}
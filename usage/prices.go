@@ -0,0 +1,27 @@
+package usage
+
+// Price is the OpenAI price of a model in USD per 1M tokens.
+type Price struct {
+	Prompt     float64 `json:"prompt"`
+	Completion float64 `json:"completion"`
+}
+
+// source https://platform.openai.com/docs/pricing, overridden by config
+var prices = map[string]Price{
+	"gpt-4o-mini-search-preview": {Prompt: 0.15, Completion: 0.60},
+	"gpt-4o-search-preview":      {Prompt: 2.50, Completion: 10.00},
+	"gpt-4o-mini":                {Prompt: 0.15, Completion: 0.60},
+}
+
+// SetPrices adds or replaces model prices, it must be called before serving requests.
+func SetPrices(overrides map[string]Price) {
+	for model, price := range overrides {
+		prices[model] = price
+	}
+}
+
+// Cost returns the price in USD of the tokens, models without a price cost nothing.
+func Cost(model string, promptTokens, completionTokens int) float64 {
+	price := prices[model]
+	return (float64(promptTokens)*price.Prompt + float64(completionTokens)*price.Completion) / 1_000_000
+}
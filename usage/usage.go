@@ -0,0 +1,188 @@
+package usage
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+	"github.com/klipach/matchguru/contract"
+	"github.com/klipach/matchguru/store"
+	"github.com/tmc/langchaingo/llms"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	firestoreUserCollection  = "users"
+	firestoreUsageCollection = "usage"
+
+	dayLayout = "2006-01-02"
+)
+
+// Entry is a single OpenAI generation in the user's usage ledger.
+type Entry struct {
+	ChatID           int       `firestore:"chat_id"`
+	Model            string    `firestore:"model"`
+	PromptTokens     int       `firestore:"prompt_tokens"`
+	CompletionTokens int       `firestore:"completion_tokens"`
+	TotalTokens      int       `firestore:"total_tokens"`
+	CostUSD          float64   `firestore:"cost_usd"`
+	CreatedAt        time.Time `firestore:"created_at"`
+	// MessageIndex and PromptVersion are set for answers, feedback on an answer reads its model and prompt from them
+	MessageIndex  int    `firestore:"message_index,omitempty"`
+	PromptVersion string `firestore:"prompt_version,omitempty"`
+}
+
+// NewEntry builds a ledger entry from the token counts OpenAI reported for the response.
+func NewEntry(model string, chatID int, resp *llms.ContentResponse, createdAt time.Time) Entry {
+	e := Entry{
+		ChatID:    chatID,
+		Model:     model,
+		CreatedAt: createdAt,
+	}
+	if resp != nil && len(resp.Choices) > 0 {
+		info := resp.Choices[0].GenerationInfo
+		e.PromptTokens, _ = info["PromptTokens"].(int)
+		e.CompletionTokens, _ = info["CompletionTokens"].(int)
+		e.TotalTokens, _ = info["TotalTokens"].(int)
+	}
+	e.CostUSD = Cost(model, e.PromptTokens, e.CompletionTokens)
+	return e
+}
+
+// Save appends the entry to users/{userID}/usage.
+func Save(ctx context.Context, userID string, e Entry) error {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return err
+	}
+	_, _, err = ledger(client, userID).Add(ctx, e)
+	return err
+}
+
+// List returns the user's entries created in [from, to).
+func List(ctx context.Context, userID string, from, to time.Time) ([]Entry, error) {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return nil, err
+	}
+	iter := ledger(client, userID).
+		Where("created_at", ">=", from).
+		Where("created_at", "<", to).
+		OrderBy("created_at", firestore.Asc).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var entries []Entry
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		var e Entry
+		if err := doc.DataTo(&e); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, nil
+}
+
+// ForAnswer returns the entry of the answer at the index of the chat messages, the latest one
+// when it was regenerated. It reports false for answers recorded without index.
+func ForAnswer(ctx context.Context, userID string, chatID, messageIndex int) (Entry, bool, error) {
+	client, err := store.Client(ctx)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	iter := ledger(client, userID).
+		Where("chat_id", "==", chatID).
+		Where("message_index", "==", messageIndex).
+		Documents(ctx)
+	defer iter.Stop()
+
+	var entries []Entry
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return Entry{}, false, err
+		}
+		var e Entry
+		if err := doc.DataTo(&e); err != nil {
+			return Entry{}, false, err
+		}
+		entries = append(entries, e)
+	}
+	e, ok := latestAnswer(entries)
+	return e, ok, nil
+}
+
+// latestAnswer picks the most recent answer entry, title generations have no prompt version.
+func latestAnswer(entries []Entry) (Entry, bool) {
+	var latest Entry
+	var ok bool
+	for _, e := range entries {
+		if e.PromptVersion == "" {
+			continue
+		}
+		if !ok || e.CreatedAt.After(latest.CreatedAt) {
+			latest, ok = e, true
+		}
+	}
+	return latest, ok
+}
+
+// Summarize aggregates entries in total, per chat and per UTC day.
+func Summarize(entries []Entry) (total contract.UsageTotals, chats []contract.ChatUsage, days []contract.DayUsage) {
+	byChat := map[int]*contract.ChatUsage{}
+	byDay := map[string]*contract.DayUsage{}
+	for _, e := range entries {
+		add(&total, e)
+
+		c, ok := byChat[e.ChatID]
+		if !ok {
+			c = &contract.ChatUsage{ChatID: e.ChatID}
+			byChat[e.ChatID] = c
+		}
+		add(&c.UsageTotals, e)
+
+		day := e.CreatedAt.UTC().Format(dayLayout)
+		d, ok := byDay[day]
+		if !ok {
+			d = &contract.DayUsage{Day: day}
+			byDay[day] = d
+		}
+		add(&d.UsageTotals, e)
+	}
+
+	chats = make([]contract.ChatUsage, 0, len(byChat))
+	for _, c := range byChat {
+		chats = append(chats, *c)
+	}
+	sort.Slice(chats, func(i, j int) bool { return chats[i].ChatID < chats[j].ChatID })
+
+	days = make([]contract.DayUsage, 0, len(byDay))
+	for _, d := range byDay {
+		days = append(days, *d)
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Day < days[j].Day })
+	return total, chats, days
+}
+
+func add(t *contract.UsageTotals, e Entry) {
+	t.Requests++
+	t.PromptTokens += e.PromptTokens
+	t.CompletionTokens += e.CompletionTokens
+	t.TotalTokens += e.TotalTokens
+	t.CostUSD += e.CostUSD
+}
+
+func ledger(client *firestore.Client, userID string) *firestore.CollectionRef {
+	return client.Collection(firestoreUserCollection).Doc(userID).Collection(firestoreUsageCollection)
+}
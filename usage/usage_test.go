@@ -0,0 +1,94 @@
+package usage
+
+import (
+	"testing"
+	"time"
+
+	"github.com/klipach/matchguru/contract"
+	"github.com/stretchr/testify/assert"
+	"github.com/tmc/langchaingo/llms"
+)
+
+func TestNewEntry(t *testing.T) {
+	now := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	resp := &llms.ContentResponse{
+		Choices: []*llms.ContentChoice{{
+			GenerationInfo: map[string]any{
+				"PromptTokens":     1_000_000,
+				"CompletionTokens": 500_000,
+				"TotalTokens":      1_500_000,
+			},
+		}},
+	}
+
+	e := NewEntry("gpt-4o-mini-search-preview", 3, resp, now)
+	assert.Equal(t, Entry{
+		ChatID:           3,
+		Model:            "gpt-4o-mini-search-preview",
+		PromptTokens:     1_000_000,
+		CompletionTokens: 500_000,
+		TotalTokens:      1_500_000,
+		CostUSD:          0.45,
+		CreatedAt:        now,
+	}, e)
+
+	assert.Equal(t, Entry{ChatID: 3, Model: "unknown", CreatedAt: now}, NewEntry("unknown", 3, nil, now))
+}
+
+func TestSetPrices(t *testing.T) {
+	SetPrices(map[string]Price{"test-model": {Prompt: 1, Completion: 2}})
+	assert.InDelta(t, 5.0, Cost("test-model", 1_000_000, 2_000_000), 1e-9)
+	assert.Zero(t, Cost("no-price-model", 1_000_000, 1_000_000))
+}
+
+func TestSummarize(t *testing.T) {
+	day1 := time.Date(2025, 5, 1, 23, 0, 0, 0, time.UTC)
+	day2 := day1.Add(2 * time.Hour)
+	entries := []Entry{
+		{ChatID: 2, PromptTokens: 10, CompletionTokens: 5, TotalTokens: 15, CostUSD: 1, CreatedAt: day1},
+		{ChatID: 1, PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30, CostUSD: 2, CreatedAt: day1},
+		{ChatID: 2, PromptTokens: 30, CompletionTokens: 15, TotalTokens: 45, CostUSD: 3, CreatedAt: day2},
+	}
+
+	total, chats, days := Summarize(entries)
+	assert.Equal(t, contract.UsageTotals{Requests: 3, PromptTokens: 60, CompletionTokens: 30, TotalTokens: 90, CostUSD: 6}, total)
+	assert.Equal(t, []contract.ChatUsage{
+		{ChatID: 1, UsageTotals: contract.UsageTotals{Requests: 1, PromptTokens: 20, CompletionTokens: 10, TotalTokens: 30, CostUSD: 2}},
+		{ChatID: 2, UsageTotals: contract.UsageTotals{Requests: 2, PromptTokens: 40, CompletionTokens: 20, TotalTokens: 60, CostUSD: 4}},
+	}, chats)
+	assert.Equal(t, []contract.DayUsage{
+		{Day: "2025-05-01", UsageTotals: contract.UsageTotals{Requests: 2, PromptTokens: 30, CompletionTokens: 15, TotalTokens: 45, CostUSD: 3}},
+		{Day: "2025-05-02", UsageTotals: contract.UsageTotals{Requests: 1, PromptTokens: 30, CompletionTokens: 15, TotalTokens: 45, CostUSD: 3}},
+	}, days)
+
+	total, chats, days = Summarize(nil)
+	assert.Zero(t, total)
+	assert.Empty(t, chats)
+	assert.Empty(t, days)
+}
+
+func TestLatestAnswer(t *testing.T) {
+	day := time.Date(2025, 5, 1, 12, 0, 0, 0, time.UTC)
+	first := Entry{Model: "gpt-4o-mini", PromptVersion: "aaa", MessageIndex: 1, CreatedAt: day}
+	regenerated := Entry{Model: "gpt-4o", PromptVersion: "bbb", MessageIndex: 1, CreatedAt: day.Add(time.Hour)}
+	title := Entry{Model: "gpt-4o-mini", CreatedAt: day.Add(2 * time.Hour)}
+
+	tests := []struct {
+		name     string
+		entries  []Entry
+		expected Entry
+		ok       bool
+	}{
+		{name: "regenerated", entries: []Entry{regenerated, first, title}, expected: regenerated, ok: true},
+		{name: "single", entries: []Entry{first}, expected: first, ok: true},
+		{name: "title only", entries: []Entry{title}},
+		{name: "none"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, ok := latestAnswer(tt.entries)
+			assert.Equal(t, tt.ok, ok)
+			assert.Equal(t, tt.expected, e)
+		})
+	}
+}